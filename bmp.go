@@ -0,0 +1,91 @@
+package qrcode
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+)
+
+const (
+	bmpFileHeaderLength = 14
+	bmpInfoHeaderLength = 40
+)
+
+// encodeBMP writes img as an uncompressed BMP. Images with a palette of at
+// most two colors are written with one bit per pixel, others with 24 bits per
+// pixel over a white background.
+func encodeBMP(w io.Writer, img image.Image, _ FormatOptions) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+
+	paletted, ok := img.(*image.Paletted)
+	bilevel := ok && len(paletted.Palette) <= 2
+
+	bitsPerPixel := 24
+	var palette []byte
+	if bilevel {
+		bitsPerPixel = 1
+		for i := range 2 {
+			c := color.Color(color.White)
+			if i < len(paletted.Palette) {
+				c = paletted.Palette[i]
+			}
+			n := over(toNRGBA(c), toNRGBA(color.White))
+			palette = append(palette, n.B, n.G, n.R, 0)
+		}
+	}
+
+	// Rows are padded to a multiple of 4 bytes.
+	rowLength := (width*bitsPerPixel + 31) / 32 * 4
+	dataOffset := bmpFileHeaderLength + bmpInfoHeaderLength + len(palette)
+
+	header := make([]byte, 0, dataOffset)
+	header = append(header, 'B', 'M')
+	header = binary.LittleEndian.AppendUint32(header, uint32(dataOffset+rowLength*height))
+	header = binary.LittleEndian.AppendUint32(header, 0)
+	header = binary.LittleEndian.AppendUint32(header, uint32(dataOffset))
+
+	header = binary.LittleEndian.AppendUint32(header, bmpInfoHeaderLength)
+	header = binary.LittleEndian.AppendUint32(header, uint32(width))
+	header = binary.LittleEndian.AppendUint32(header, uint32(height))
+	header = binary.LittleEndian.AppendUint16(header, 1)
+	header = binary.LittleEndian.AppendUint16(header, uint16(bitsPerPixel))
+	header = binary.LittleEndian.AppendUint32(header, 0) // BI_RGB
+	header = binary.LittleEndian.AppendUint32(header, uint32(rowLength*height))
+	header = binary.LittleEndian.AppendUint32(header, 2835) // 72 dpi
+	header = binary.LittleEndian.AppendUint32(header, 2835)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(palette)/4))
+	header = binary.LittleEndian.AppendUint32(header, 0)
+	header = append(header, palette...)
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	white := toNRGBA(color.White)
+	row := make([]byte, rowLength)
+
+	// Rows are stored bottom-up.
+	for y := b.Max.Y - 1; y >= b.Min.Y; y-- {
+		clear(row)
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := x - b.Min.X
+			if bilevel {
+				if paletted.ColorIndexAt(x, y) != 0 {
+					row[i/8] |= 0x80 >> (i % 8)
+				}
+				continue
+			}
+
+			n := over(toNRGBA(img.At(x, y)), white)
+			row[i*3], row[i*3+1], row[i*3+2] = n.B, n.G, n.R
+		}
+
+		if _, err := w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
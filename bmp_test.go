@@ -0,0 +1,55 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestEncodeBMP(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	bitmap := q.Bitmap()
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WriteFormat(&buf, "bmp", FormatOptions{Size: -1}))
+	data := buf.Bytes()
+
+	size := len(bitmap)
+	rowLength := (size + 31) / 32 * 4
+	offset := int(binary.LittleEndian.Uint32(data[10:]))
+
+	assert.Equal(t, string(data[:2]), "BM")
+	assert.Equal(t, int(binary.LittleEndian.Uint32(data[2:])), len(data))
+	assert.Equal(t, int(binary.LittleEndian.Uint32(data[18:])), size)
+	assert.Equal(t, binary.LittleEndian.Uint16(data[28:]), uint16(1))
+	assert.Equal(t, offset, bmpFileHeaderLength+bmpInfoHeaderLength+8)
+	assert.Equal(t, len(data), offset+rowLength*size)
+
+	for y := range size {
+		row := data[offset+(size-1-y)*rowLength:]
+		for x := range size {
+			assert.Equal(t, row[x/8]&(0x80>>(x%8)) != 0, bitmap[y][x])
+		}
+	}
+}
+
+func TestEncodeBMPTrueColor(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+	img.SetNRGBA(0, 0, color.NRGBA{0x10, 0x20, 0x30, 0xff})
+	img.SetNRGBA(2, 1, color.NRGBA{0xff, 0x00, 0x00, 0xff})
+
+	var buf bytes.Buffer
+	assert.NoError(t, encodeBMP(&buf, img, FormatOptions{}))
+	data := buf.Bytes()
+
+	assert.Equal(t, binary.LittleEndian.Uint16(data[28:]), uint16(24))
+	pixels := data[bmpFileHeaderLength+bmpInfoHeaderLength:]
+	// The bottom row comes first, padded from 9 to 12 bytes.
+	assert.Equal(t, pixels[:12], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0xff, 0, 0, 0})
+	assert.Equal(t, pixels[12:15], []byte{0x30, 0x20, 0x10})
+}
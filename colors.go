@@ -0,0 +1,97 @@
+package qrcode
+
+import (
+	"image/color"
+	"math"
+)
+
+// colorSum accumulates the samples of a pixel with premultiplied alpha, so
+// semi-transparent colors blend correctly.
+type colorSum struct {
+	r, g, b, a float64
+	weight     float64
+}
+
+func (s *colorSum) add(c color.NRGBA) {
+	s.addWeighted(c, 1)
+}
+
+func (s *colorSum) addWeighted(c color.NRGBA, weight float64) {
+	a := float64(c.A) * weight
+	s.r += float64(c.R) * a
+	s.g += float64(c.G) * a
+	s.b += float64(c.B) * a
+	s.a += a
+	s.weight += weight
+}
+
+// average returns the weighted mean of the samples added to s.
+func (s *colorSum) average() color.NRGBA {
+	if s.a == 0 {
+		return color.NRGBA{}
+	}
+
+	return color.NRGBA{
+		R: uint8(s.r/s.a + 0.5),
+		G: uint8(s.g/s.a + 0.5),
+		B: uint8(s.b/s.a + 0.5),
+		A: uint8(s.a/s.weight + 0.5),
+	}
+}
+
+// over composites c over background.
+func over(c, background color.NRGBA) color.NRGBA {
+	k := float64(0xff-c.A) / 0xff
+
+	var sum colorSum
+	sum.addWeighted(c, 1)
+	sum.addWeighted(background, k)
+
+	n := sum.average()
+	n.A = uint8(float64(c.A) + float64(background.A)*k + 0.5)
+	return n
+}
+
+func toNRGBA(c color.Color) color.NRGBA {
+	return color.NRGBAModel.Convert(c).(color.NRGBA)
+}
+
+// onWhite returns c as it appears over a white page.
+func onWhite(c color.Color) color.NRGBA {
+	return over(toNRGBA(c), toNRGBA(color.White))
+}
+
+// sameColor reports whether a and b are both nil or draw the same color. It
+// does not use ==, which panics on colors that are not comparable.
+func sameColor(a, b color.Color) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return toNRGBA(a) == toNRGBA(b)
+}
+
+// relativeLuminance returns the relative luminance of c as defined by WCAG
+// 2.x, ignoring alpha.
+func relativeLuminance(c color.Color) float64 {
+	n := toNRGBA(c)
+
+	linear := func(v uint8) float64 {
+		s := float64(v) / 0xff
+		if s <= 0.04045 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+
+	return 0.2126*linear(n.R) + 0.7152*linear(n.G) + 0.0722*linear(n.B)
+}
+
+// ContrastRatio returns the WCAG contrast ratio between a and b, from 1 to 21,
+// ignoring alpha.
+func ContrastRatio(a, b color.Color) float64 {
+	la, lb := relativeLuminance(a), relativeLuminance(b)
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
@@ -0,0 +1,203 @@
+package qrcode
+
+import "image"
+
+// contour is a closed boundary of a region of modules.
+type contour struct {
+	// points are the corners of the boundary, in modules. The region is on
+	// the right of every edge, so outer boundaries run clockwise and holes
+	// counter-clockwise, with y growing downwards.
+	points polygon
+
+	// region is the index of the region bounded, as labelled by regions.
+	region int
+}
+
+// regions labels the 4-connected regions of the set modules of bitmap, in
+// the order they are first met scanning rows from the top. Unset modules
+// are labelled -1. It returns the labels and the number of regions.
+func regions(bitmap [][]bool) ([][]int, int) {
+	labels := make([][]int, len(bitmap))
+	for y, row := range bitmap {
+		labels[y] = make([]int, len(row))
+		for x := range row {
+			labels[y][x] = -1
+		}
+	}
+
+	n := 0
+	var stack []image.Point
+	for y, row := range bitmap {
+		for x, set := range row {
+			if !set || labels[y][x] >= 0 {
+				continue
+			}
+
+			labels[y][x] = n
+			stack = append(stack[:0], image.Pt(x, y))
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+
+				for _, d := range [4]image.Point{{1, 0}, {0, 1}, {-1, 0}, {0, -1}} {
+					q := p.Add(d)
+					if q.Y >= 0 && q.Y < len(bitmap) && q.X >= 0 && q.X < len(bitmap[q.Y]) &&
+						bitmap[q.Y][q.X] && labels[q.Y][q.X] < 0 {
+						labels[q.Y][q.X] = n
+						stack = append(stack, q)
+					}
+				}
+			}
+			n++
+		}
+	}
+
+	return labels, n
+}
+
+// traceContours returns the boundaries of the regions of set modules of
+// bitmap, with collinear points removed. Regions touching only at a corner
+// get separate boundaries.
+func traceContours(bitmap [][]bool) []contour {
+	labels, _ := regions(bitmap)
+	size := len(bitmap)
+
+	set := func(x, y int) bool {
+		return y >= 0 && y < size && x >= 0 && x < size && bitmap[y][x]
+	}
+
+	// Every edge between a set module and an unset one is directed with
+	// the set module on its right, and indexed by its start.
+	type edge struct {
+		from, dir image.Point
+		used      bool
+	}
+	var edges []edge
+	starts := map[image.Point][]int{}
+	add := func(from, dir image.Point) {
+		starts[from] = append(starts[from], len(edges))
+		edges = append(edges, edge{from: from, dir: dir})
+	}
+
+	for y := range size {
+		for x := range size {
+			if !bitmap[y][x] {
+				continue
+			}
+			if !set(x, y-1) {
+				add(image.Pt(x, y), image.Pt(1, 0))
+			}
+			if !set(x+1, y) {
+				add(image.Pt(x+1, y), image.Pt(0, 1))
+			}
+			if !set(x, y+1) {
+				add(image.Pt(x+1, y+1), image.Pt(-1, 0))
+			}
+			if !set(x-1, y) {
+				add(image.Pt(x, y+1), image.Pt(0, -1))
+			}
+		}
+	}
+
+	var contours []contour
+	for i := range edges {
+		if edges[i].used {
+			continue
+		}
+
+		// The module on the right of the first edge.
+		e := edges[i]
+		cell := e.from
+		switch e.dir {
+		case image.Pt(0, 1):
+			cell.X--
+		case image.Pt(-1, 0):
+			cell = cell.Sub(image.Pt(1, 1))
+		case image.Pt(0, -1):
+			cell.Y--
+		}
+		c := contour{region: labels[cell.Y][cell.X]}
+
+		for j := i; !edges[j].used; {
+			e := &edges[j]
+			e.used = true
+
+			c.points = append(c.points, toPoint(e.from))
+
+			// Where two boundaries meet at a corner, turning right keeps
+			// to the region being traced.
+			to := e.from.Add(e.dir)
+			right := image.Pt(-e.dir.Y, e.dir.X)
+			next := -1
+			for _, k := range starts[to] {
+				if edges[k].used && k != i {
+					continue
+				}
+				if next < 0 || edges[k].dir == right {
+					next = k
+				}
+			}
+			if next < 0 {
+				break
+			}
+			j = next
+		}
+
+		c.points = simplifyContour(c.points)
+		contours = append(contours, c)
+	}
+
+	return contours
+}
+
+func toPoint(p image.Point) point {
+	return point{float64(p.X), float64(p.Y)}
+}
+
+// simplifyContour removes the points of a closed rectilinear boundary lying
+// on a straight line between their neighbours.
+func simplifyContour(pg polygon) polygon {
+	n := len(pg)
+	var out polygon
+	for i, p := range pg {
+		prev, next := pg[(i+n-1)%n], pg[(i+1)%n]
+		if (prev.x == p.x && p.x == next.x) || (prev.y == p.y && p.y == next.y) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// inset returns the boundary moved by d into its region. The result is
+// valid for d up to half a module.
+func (c contour) inset(d float64) polygon {
+	n := len(c.points)
+	out := make(polygon, n)
+	for i, p := range c.points {
+		prev, next := c.points[(i+n-1)%n], c.points[(i+1)%n]
+
+		// The inward normal of an edge is its direction turned right.
+		in := unit(point{p.x - prev.x, p.y - prev.y})
+		outgoing := unit(point{next.x - p.x, next.y - p.y})
+		out[i] = point{
+			p.x + d*(-in.y-outgoing.y),
+			p.y + d*(in.x+outgoing.x),
+		}
+	}
+	return out
+}
+
+// unit returns the direction of an axis-aligned vector.
+func unit(v point) point {
+	sign := func(f float64) float64 {
+		switch {
+		case f > 0:
+			return 1
+		case f < 0:
+			return -1
+		}
+		return 0
+	}
+	return point{sign(v.x), sign(v.y)}
+}
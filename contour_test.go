@@ -0,0 +1,97 @@
+package qrcode
+
+import (
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+// parseBitmap returns the bitmap drawn by rows of '#' and '.'.
+func parseBitmap(rows ...string) [][]bool {
+	bitmap := make([][]bool, len(rows))
+	for y, row := range rows {
+		bitmap[y] = make([]bool, len(row))
+		for x, c := range row {
+			bitmap[y][x] = c == '#'
+		}
+	}
+	return bitmap
+}
+
+// signedArea returns the area of pg, positive if it runs clockwise with y
+// growing downwards.
+func signedArea(pg polygon) float64 {
+	var sum float64
+	for i, p := range pg {
+		q := pg[(i+1)%len(pg)]
+		sum += p.x*q.y - q.x*p.y
+	}
+	return sum / 2
+}
+
+func TestTraceContours(t *testing.T) {
+	contours := traceContours(parseBitmap(
+		"....",
+		".#..",
+		"....",
+		"....",
+	))
+	assert.Equal(t, len(contours), 1)
+	assert.Equal(t, contours[0].points, polygon{{1, 1}, {2, 1}, {2, 2}, {1, 2}})
+	assert.Equal(t, contours[0].inset(0.25), polygon{{1.25, 1.25}, {1.75, 1.25}, {1.75, 1.75}, {1.25, 1.75}})
+
+	// Modules touching at a corner are separate regions.
+	bitmap := parseBitmap(
+		"#...",
+		".#..",
+		"....",
+		"....",
+	)
+	labels, n := regions(bitmap)
+	assert.Equal(t, n, 2)
+	assert.Equal(t, labels[1][1], 1)
+	contours = traceContours(bitmap)
+	assert.Equal(t, len(contours), 2)
+	for _, c := range contours {
+		assert.Equal(t, len(c.points), 4)
+	}
+
+	// A ring has an outer boundary and a hole running the other way.
+	contours = traceContours(parseBitmap(
+		"###.",
+		"#.#.",
+		"###.",
+		"....",
+	))
+	assert.Equal(t, len(contours), 2)
+	assert.Equal(t, signedArea(contours[0].points), 9.0)
+	assert.Equal(t, signedArea(contours[1].points), -1.0)
+	assert.Equal(t, signedArea(contours[1].inset(0.25)), -2.25)
+}
+
+func TestTraceContoursArea(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	bitmap := q.Bitmap()
+
+	dark := 0
+	for _, row := range bitmap {
+		for _, d := range row {
+			if d {
+				dark++
+			}
+		}
+	}
+
+	_, n := regions(bitmap)
+	seen := make([]bool, n)
+	var area float64
+	for _, c := range traceContours(bitmap) {
+		area += signedArea(c.points)
+		seen[c.region] = true
+	}
+	assert.Equal(t, area, float64(dark))
+	for _, s := range seen {
+		assert.True(t, s)
+	}
+}
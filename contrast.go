@@ -0,0 +1,191 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+)
+
+// DefaultMinContrast is the default minimum contrast ratio between the
+// foreground and background colors of a QRCode.
+const DefaultMinContrast = 3.0
+
+var (
+	// ErrLowContrast is reported when the foreground and background colors
+	// are too close for readers to tell modules apart.
+	ErrLowContrast = errors.New("colors have too little contrast")
+
+	// ErrReversedPolarity is reported when the foreground is lighter than
+	// the background and the QRCode is not marked as inverted.
+	ErrReversedPolarity = errors.New("foreground is lighter than the background")
+
+	// ErrInverted is reported when an inverted QRCode is written, since
+	// some older readers do not handle light modules on a dark background.
+	ErrInverted = errors.New("QRCode is inverted, which some readers cannot scan")
+
+	// ErrColorIgnored is reported when the gradient or a region or eye
+	// color cannot be read against the background, and the modules are
+	// drawn in the foreground color instead.
+	ErrColorIgnored = errors.New("color cannot be read and is replaced by the foreground")
+)
+
+// ContrastPolicy is what happens when a QRCode is written with colors that
+// fail CheckColors. The policy applies to the methods writing to an
+// io.Writer or a file, and to Render; methods returning an image or bytes,
+// such as Image and PNG, do not check the colors.
+type ContrastPolicy int
+
+const (
+	// ContrastDefault rejects colors with too little contrast, like
+	// ContrastReject, and reports the other problems to OnWarning, like
+	// ContrastWarn.
+	ContrastDefault ContrastPolicy = iota
+	// ContrastWarn reports the problem to OnWarning and writes the
+	// QRCode anyway.
+	ContrastWarn
+	// ContrastReject returns the problem as an error without writing the
+	// QRCode.
+	ContrastReject
+	// ContrastIgnore skips the checks.
+	ContrastIgnore
+)
+
+// WithContrastPolicy sets what happens when the colors of the QRCode have
+// less than minContrast contrast or a reversed polarity. Zero uses
+// DefaultMinContrast.
+func (q *QRCode) WithContrastPolicy(policy ContrastPolicy, minContrast float64) *QRCode {
+	q.ContrastPolicy = policy
+	q.MinContrast = minContrast
+	return q
+}
+
+// WithInverted marks the QRCode as intentionally drawn with light modules on
+// a dark background, swapping its colors if the foreground is darker. An
+// inverted QRCode is reported with ErrInverted whenever it is written.
+func (q *QRCode) WithInverted() *QRCode {
+	q.Inverted = true
+	if relativeLuminance(q.ForegroundColor) < relativeLuminance(q.BackgroundColor) {
+		q.ForegroundColor, q.BackgroundColor = q.BackgroundColor, q.ForegroundColor
+	}
+	return q
+}
+
+// SetColors sets the foreground and background colors of the QRCode and
+// checks them following its ContrastPolicy. With ContrastReject, colors that
+// fail the checks are returned as an error and the QRCode is left unchanged.
+func (q *QRCode) SetColors(foreground, background color.Color) error {
+	oldForeground, oldBackground := q.ForegroundColor, q.BackgroundColor
+	q.ForegroundColor, q.BackgroundColor = foreground, background
+
+	if err := q.checkColors(); err != nil {
+		q.ForegroundColor, q.BackgroundColor = oldForeground, oldBackground
+		return err
+	}
+	return nil
+}
+
+// CheckColors returns an error wrapping ErrLowContrast if the contrast ratio
+// between the foreground and background colors is below MinContrast, or
+// ErrReversedPolarity if the foreground is lighter and the QRCode is not
+// Inverted. It then returns an error wrapping ErrColorIgnored if the gradient
+// or a region or eye color would be replaced by the foreground. Transparent
+// colors are checked over a white page.
+func (q *QRCode) CheckColors() error {
+	minContrast := q.MinContrast
+	if minContrast == 0 {
+		minContrast = DefaultMinContrast
+	}
+
+	foreground, background := q.pageColors()
+	if ratio := ContrastRatio(foreground, background); ratio < minContrast {
+		return fmt.Errorf("%w: %.2f (minimum is %g)", ErrLowContrast, ratio, minContrast)
+	}
+	if relativeLuminance(foreground) > relativeLuminance(background) && !q.Inverted {
+		return ErrReversedPolarity
+	}
+
+	return q.checkStyleColors()
+}
+
+// checkStyleColors returns an error wrapping ErrColorIgnored for the first
+// style color of the QRCode that cannot be read against the background.
+func (q *QRCode) checkStyleColors() error {
+	if q.Gradient != nil {
+		if err := q.Gradient.Validate(q.BackgroundColor); err != nil {
+			return fmt.Errorf("%w: Gradient: %v", ErrColorIgnored, err)
+		}
+	}
+
+	type styleColor struct {
+		name string
+		c    color.Color
+	}
+	colors := []styleColor{
+		{"DataColor", q.DataColor},
+		{"FinderColor", q.FinderColor},
+		{"AlignmentColor", q.AlignmentColor},
+	}
+	for i := range 3 {
+		colors = append(colors,
+			styleColor{fmt.Sprintf("FinderStyle.OuterColors[%d]", i), q.FinderStyle.OuterColors[i]},
+			styleColor{fmt.Sprintf("FinderStyle.InnerColors[%d]", i), q.FinderStyle.InnerColors[i]})
+	}
+
+	for _, s := range colors {
+		if s.c == nil {
+			continue
+		}
+		if err := q.styleColorError(s.c); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrColorIgnored, s.name, err)
+		}
+	}
+	return nil
+}
+
+// styleColorError returns why modules cannot be drawn in c, or nil if they
+// can: c must have the polarity of the foreground and enough contrast
+// against the background, over a white page.
+func (q *QRCode) styleColorError(c color.Color) error {
+	foreground, background := q.pageColors()
+	page := over(toNRGBA(c), background)
+
+	darker := relativeLuminance(foreground) < relativeLuminance(background)
+	if (relativeLuminance(page) < relativeLuminance(background)) != darker {
+		return errors.New("color has the opposite polarity to the foreground")
+	}
+	if ratio := ContrastRatio(page, background); ratio < minStyleContrast {
+		return fmt.Errorf("contrast ratio %.2f against the background (minimum is %d)", ratio, minStyleContrast)
+	}
+	return nil
+}
+
+// checkColors applies the ContrastPolicy before the QRCode is written,
+// returning an error only if the colors are rejected.
+func (q *QRCode) checkColors() error {
+	if q.ContrastPolicy == ContrastIgnore {
+		return nil
+	}
+
+	if err := q.CheckColors(); err != nil {
+		if q.ContrastPolicy == ContrastReject ||
+			q.ContrastPolicy == ContrastDefault && errors.Is(err, ErrLowContrast) {
+			return err
+		}
+		q.warn(err)
+		return nil
+	}
+
+	if foreground, background := q.pageColors(); q.Inverted &&
+		relativeLuminance(foreground) > relativeLuminance(background) {
+		q.warn(ErrInverted)
+	}
+	return nil
+}
+
+// pageColors returns the foreground and background colors as they appear on
+// a white page.
+func (q *QRCode) pageColors() (foreground, background color.NRGBA) {
+	background = onWhite(q.BackgroundColor)
+	foreground = over(toNRGBA(q.ForegroundColor), background)
+	return foreground, background
+}
@@ -0,0 +1,131 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestContrastRatio(t *testing.T) {
+	assert.Equal(t, ContrastRatio(color.Black, color.White), 21.0)
+	assert.Equal(t, ContrastRatio(color.White, color.Black), 21.0)
+	assert.Equal(t, ContrastRatio(navy, navy), 1.0)
+}
+
+func TestCheckColors(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	assert.NoError(t, q.CheckColors())
+
+	q.WithColors(lightGrey, color.White)
+	assert.True(t, errors.Is(q.CheckColors(), ErrLowContrast))
+
+	// A transparent background is checked over white.
+	q.WithColors(navy, color.Transparent)
+	assert.NoError(t, q.CheckColors())
+
+	q.WithColors(color.White, navy)
+	assert.True(t, errors.Is(q.CheckColors(), ErrReversedPolarity))
+	q.Inverted = true
+	assert.NoError(t, q.CheckColors())
+
+	q.WithColors(navy, color.White).WithContrastPolicy(ContrastWarn, 21)
+	assert.True(t, errors.Is(q.CheckColors(), ErrLowContrast))
+}
+
+func TestContrastPolicy(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	var warnings []error
+	q.OnWarning = func(err error) { warnings = append(warnings, err) }
+	q.WithColors(lightGrey, color.White)
+
+	// The default policy rejects low contrast and warns about the rest.
+	var buf bytes.Buffer
+	assert.True(t, errors.Is(q.Write(64, &buf), ErrLowContrast))
+	assert.Equal(t, buf.Len(), 0)
+	assert.Equal(t, len(warnings), 0)
+
+	q.WithColors(color.White, navy)
+	assert.NoError(t, q.Write(64, &buf))
+	assert.Equal(t, len(warnings), 1)
+	assert.True(t, errors.Is(warnings[0], ErrReversedPolarity))
+
+	q.WithColors(lightGrey, color.White).WithContrastPolicy(ContrastWarn, 0)
+	assert.NoError(t, q.Write(64, &buf))
+	assert.Equal(t, len(warnings), 2)
+	assert.True(t, errors.Is(warnings[1], ErrLowContrast))
+
+	q.WithContrastPolicy(ContrastReject, 0)
+	buf.Reset()
+	assert.True(t, errors.Is(q.WriteSVG(&buf, SVGOptions{}), ErrLowContrast))
+	assert.True(t, errors.Is(q.WriteFormat(&buf, "png", FormatOptions{}), ErrLowContrast))
+	assert.True(t, errors.Is(q.Render(&buf, TextRenderer{}), ErrLowContrast))
+	assert.Equal(t, buf.Len(), 0)
+
+	q.WithContrastPolicy(ContrastIgnore, 0)
+	assert.NoError(t, q.Write(64, &buf))
+	assert.Equal(t, len(warnings), 2)
+}
+
+func TestWarningsWithoutHandler(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithColors(color.White, navy)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.Write(64, &buf))
+	assert.NotEqual(t, buf.Len(), 0)
+	assert.Equal(t, logged.Len(), 0)
+}
+
+func TestSetColors(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.OnWarning = func(error) {}
+
+	assert.True(t, errors.Is(q.SetColors(lightGrey, color.White), ErrLowContrast))
+	assert.Equal(t, q.ForegroundColor, color.Color(color.Black))
+
+	q.WithContrastPolicy(ContrastWarn, 0)
+	assert.NoError(t, q.SetColors(lightGrey, color.White))
+	assert.Equal(t, q.ForegroundColor, lightGrey)
+
+	q.WithContrastPolicy(ContrastReject, 0).WithColors(color.Black, color.White)
+	assert.True(t, errors.Is(q.SetColors(lightGrey, color.White), ErrLowContrast))
+	assert.Equal(t, q.ForegroundColor, color.Color(color.Black))
+
+	assert.NoError(t, q.SetColors(navy, color.White))
+	assert.Equal(t, q.ForegroundColor, navy)
+}
+
+func TestWithInverted(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	var warnings []error
+	q.OnWarning = func(err error) { warnings = append(warnings, err) }
+
+	q.WithContrastPolicy(ContrastReject, 0).WithInverted()
+	assert.Equal(t, q.ForegroundColor, color.Color(color.White))
+	assert.Equal(t, q.BackgroundColor, color.Color(color.Black))
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.Write(64, &buf))
+	assert.Equal(t, len(warnings), 1)
+	assert.True(t, errors.Is(warnings[0], ErrInverted))
+
+	// Already light on dark colors are kept.
+	q.WithColors(color.White, navy).WithInverted()
+	assert.Equal(t, q.BackgroundColor, navy)
+}
@@ -0,0 +1,115 @@
+package qrcode
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Rotation is a clockwise rotation in steps of 90 degrees.
+type Rotation int
+
+const (
+	Rotate0 Rotation = iota
+	Rotate90
+	Rotate180
+	Rotate270
+)
+
+// DrawOptions configures QRCode.DrawInto.
+type DrawOptions struct {
+	Rotation Rotation
+
+	// KnockOut paints the background color over the symbol and its quiet
+	// zone. Otherwise only the dark modules are drawn, over the existing
+	// content of the image.
+	KnockOut bool
+}
+
+// DrawInto draws the QRCode into dst, centred in r, with the largest whole
+// number of pixels per module that fits. A framed QRCode is drawn with its
+// frame. It returns an error if r is smaller than one pixel per module.
+func (q *QRCode) DrawInto(dst draw.Image, r image.Rectangle, opts DrawOptions) error {
+	m := q.Matrix(0)
+
+	background := q.BackgroundColor
+	if !opts.KnockOut {
+		background = color.Transparent
+	}
+
+	var img *image.NRGBA
+	if q.frame != nil {
+		f := q.frame.layout(m, q.ForegroundColor, q.BackgroundColor)
+
+		// A quarter turn swaps the width and height of the frame.
+		w, h := f.w, f.h
+		if opts.Rotation%2 != 0 {
+			w, h = h, w
+		}
+		moduleSize := int(math.Min(float64(r.Dx())/w, float64(r.Dy())/h))
+		if moduleSize < 1 {
+			return errors.New("rectangle is too small to draw the QR Code")
+		}
+
+		scale := float64(moduleSize)
+		img = q.drawFramed(m, f, framedRaster{
+			width:   int(math.Round(f.w * scale)),
+			height:  int(math.Round(f.h * scale)),
+			originX: math.Round(-f.x * scale),
+			originY: math.Round(-f.y * scale),
+			scale:   scale,
+		}, toNRGBA(background))
+	} else {
+		moduleSize := min(r.Dx(), r.Dy()) / m.size
+		if moduleSize < 1 {
+			return errors.New("rectangle is too small to draw the QR Code")
+		}
+
+		l := rasterLayout{size: moduleSize * m.size, modules: 1, pixels: moduleSize}
+		if q.isPlain() {
+			img = plainImage(m.dark, l, RasterNRGBA, q.ForegroundColor, background).(*image.NRGBA)
+		} else {
+			img = q.antialiasedImage(l, toNRGBA(background))
+		}
+	}
+	img = rotate(img, opts.Rotation)
+
+	size := img.Rect.Size()
+	origin := r.Min.Add(image.Pt((r.Dx()-size.X)/2, (r.Dy()-size.Y)/2))
+	draw.Draw(dst, image.Rectangle{origin, origin.Add(size)}, img, image.Point{}, draw.Over)
+
+	return nil
+}
+
+// rotate returns img rotated clockwise by rotation.
+func rotate(img *image.NRGBA, rotation Rotation) *image.NRGBA {
+	rotation = (rotation%4 + 4) % 4
+	if rotation == Rotate0 {
+		return img
+	}
+
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	out := image.NewNRGBA(image.Rect(0, 0, h, w))
+	if rotation == Rotate180 {
+		out = image.NewNRGBA(image.Rect(0, 0, w, h))
+	}
+
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+			switch rotation {
+			case Rotate90:
+				dx, dy = h-1-y, x
+			case Rotate180:
+				dx, dy = w-1-x, h-1-y
+			default:
+				dx, dy = y, w-1-x
+			}
+			out.SetNRGBA(dx, dy, img.NRGBAAt(x, y))
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,71 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestDrawInto(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	bitmap := q.Bitmap()
+	size := len(bitmap)
+
+	red := color.NRGBA{0xff, 0x00, 0x00, 0xff}
+	dst := image.NewNRGBA(image.Rect(0, 0, 400, 300))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(red), image.Point{}, draw.Src)
+
+	r := image.Rect(50, 20, 350, 280)
+	assert.NoError(t, q.DrawInto(dst, r, DrawOptions{Rotation: Rotate90}))
+
+	moduleSize := r.Dy() / size
+	origin := image.Pt(50+(r.Dx()-moduleSize*size)/2, 20+(r.Dy()-moduleSize*size)/2)
+
+	for y := range size {
+		for x := range size {
+			// Rotating clockwise moves module x, y to size-1-y, x.
+			px := origin.X + (size-1-y)*moduleSize + moduleSize/2
+			py := origin.Y + x*moduleSize + moduleSize/2
+
+			want := red
+			if bitmap[y][x] {
+				want = toNRGBA(q.ForegroundColor)
+			}
+			assert.Equal(t, dst.NRGBAAt(px, py), want)
+		}
+	}
+
+	assert.Equal(t, dst.NRGBAAt(origin.X-1, origin.Y), red)
+}
+
+func TestDrawIntoKnockOut(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithModuleShape(ShapeCircle)
+
+	dst := image.NewRGBA(image.Rect(0, 0, 200, 200))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	assert.NoError(t, q.DrawInto(dst, dst.Bounds(), DrawOptions{KnockOut: true}))
+
+	// The quiet zone is painted with the background.
+	assert.Equal(t, toNRGBA(dst.At(10, 10)), toNRGBA(q.BackgroundColor))
+	assert.Equal(t, toNRGBA(dst.At(199, 199)), toNRGBA(color.Black))
+
+	assert.Error(t, q.DrawInto(dst, image.Rect(0, 0, 10, 10), DrawOptions{}))
+}
+
+func TestRotate(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	a := color.NRGBA{1, 2, 3, 4}
+	img.SetNRGBA(0, 0, a)
+
+	assert.Equal(t, rotate(img, Rotate90).NRGBAAt(1, 0), a)
+	assert.Equal(t, rotate(img, Rotate180).NRGBAAt(1, 1), a)
+	assert.Equal(t, rotate(img, Rotate270).NRGBAAt(0, 1), a)
+	assert.Equal(t, rotate(img, -1).NRGBAAt(0, 1), a)
+}
@@ -0,0 +1,267 @@
+package qrcode
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// DefaultDXFModuleSize is the default size of a module in DXF output, in
+// millimetres.
+const DefaultDXFModuleSize = 1.0
+
+// DXFOptions configures the DXF output of a QRCode.
+type DXFOptions struct {
+	// ModuleSize is the size of a module in millimetres. Zero uses
+	// DefaultDXFModuleSize.
+	ModuleSize float64
+
+	// QuietZone is the width of the quiet zone in modules. Zero keeps the
+	// default quiet zone and NoQuietZone removes it.
+	QuietZone int
+
+	// Layer is the layer of the outlines. Empty uses layer "0". It cannot
+	// contain control characters or any of <>/\":;?*|=`.
+	Layer string
+}
+
+// dxfReservedChars cannot appear in the names of DXF symbol table entries.
+const dxfReservedChars = "<>/\\\":;?*|=`"
+
+// WriteDXF writes an AutoCAD 2000 DXF drawing of the QRCode for CAD and CNC
+// software. Every merged region of dark modules is drawn as closed LWPOLYLINE
+// entities: its outer boundary and the boundaries of its holes. The origin is
+// the bottom-left corner of the quiet zone, with y pointing up.
+func (q *QRCode) WriteDXF(out io.Writer, opts DXFOptions) error {
+	if opts.ModuleSize == 0 {
+		opts.ModuleSize = DefaultDXFModuleSize
+	}
+	if opts.ModuleSize < 0 {
+		return fmt.Errorf("DXF module size %g is negative", opts.ModuleSize)
+	}
+	if opts.Layer == "" {
+		opts.Layer = "0"
+	}
+	if strings.ContainsFunc(opts.Layer, unicode.IsControl) || strings.ContainsAny(opts.Layer, dxfReservedChars) {
+		return fmt.Errorf("invalid DXF layer name %q", opts.Layer)
+	}
+	if err := q.checkUnframed("DXF"); err != nil {
+		return err
+	}
+
+	m := q.Matrix(opts.QuietZone)
+	height := float64(m.size) * opts.ModuleSize
+
+	// The sections after the header are written first, as the header
+	// records the next free handle.
+	d := &dxfWriter{handle: 1}
+	d.writeTables(opts.Layer)
+	d.writeBlocks()
+
+	d.section("ENTITIES")
+	for _, c := range traceContours(m.dark) {
+		d.entity("LWPOLYLINE", d.modelSpace, opts.Layer)
+		d.group(100, "AcDbPolyline")
+		d.group(90, fmt.Sprint(len(c.points)))
+		d.group(70, "1") // Closed.
+		for _, p := range c.points {
+			d.number(10, p.x*opts.ModuleSize)
+			d.number(20, height-p.y*opts.ModuleSize)
+		}
+	}
+	d.group(0, "ENDSEC")
+
+	d.writeObjects()
+	d.group(0, "EOF")
+	body := d.buf
+
+	// $INSUNITS 4 and $MEASUREMENT 1 are millimetres.
+	d.buf = bytes.Buffer{}
+	d.section("HEADER")
+	d.group(9, "$ACADVER")
+	d.group(1, "AC1015")
+	d.group(9, "$HANDSEED")
+	d.group(5, fmt.Sprintf("%X", d.handle))
+	d.group(9, "$INSUNITS")
+	d.group(70, "4")
+	d.group(9, "$MEASUREMENT")
+	d.group(70, "1")
+	d.group(0, "ENDSEC")
+	d.section("CLASSES")
+	d.group(0, "ENDSEC")
+
+	w := bufio.NewWriter(out)
+	w.Write(d.buf.Bytes())
+	w.Write(body.Bytes())
+	return w.Flush()
+}
+
+// dxfWriter writes the group codes of a DXF file, numbering the objects with
+// handles.
+type dxfWriter struct {
+	buf    bytes.Buffer
+	handle int
+
+	// The handles of the block records owning the entities.
+	modelSpace, paperSpace string
+}
+
+func (d *dxfWriter) group(code int, value string) {
+	fmt.Fprintf(&d.buf, "%d\n%s\n", code, value)
+}
+
+func (d *dxfWriter) number(code int, v float64) {
+	d.group(code, formatNumber(v))
+}
+
+// next returns a new handle.
+func (d *dxfWriter) next() string {
+	h := fmt.Sprintf("%X", d.handle)
+	d.handle++
+	return h
+}
+
+func (d *dxfWriter) section(name string) {
+	d.group(0, "SECTION")
+	d.group(2, name)
+}
+
+// object starts an object of type kind owned by owner, with the given
+// subclasses, and returns its handle.
+func (d *dxfWriter) object(kind, owner string, subclasses ...string) string {
+	h := d.next()
+	d.group(0, kind)
+	if kind == "DIMSTYLE" {
+		d.group(105, h)
+	} else {
+		d.group(5, h)
+	}
+	d.group(330, owner)
+	for _, s := range subclasses {
+		d.group(100, s)
+	}
+	return h
+}
+
+// entity starts an entity of type kind on layer, owned by a block record.
+func (d *dxfWriter) entity(kind, owner, layer string) {
+	d.object(kind, owner, "AcDbEntity")
+	if owner == d.paperSpace {
+		d.group(67, "1")
+	}
+	d.group(8, layer)
+}
+
+// writeTables writes the symbol tables required by AutoCAD, with the layer of
+// the outlines.
+func (d *dxfWriter) writeTables(layer string) {
+	d.section("TABLES")
+
+	type group struct {
+		code  int
+		value string
+	}
+	type entry struct {
+		name   string
+		groups []group
+	}
+	table := func(name, subclass string, entries ...entry) []string {
+		d.group(0, "TABLE")
+		d.group(2, name)
+		h := d.next()
+		d.group(5, h)
+		d.group(330, "0")
+		d.group(100, "AcDbSymbolTable")
+		d.group(70, fmt.Sprint(len(entries)))
+		if name == "DIMSTYLE" {
+			d.group(100, "AcDbDimStyleTable")
+		}
+
+		handles := make([]string, len(entries))
+		for i, e := range entries {
+			handles[i] = d.object(name, h, "AcDbSymbolTableRecord", subclass)
+			d.group(2, e.name)
+			d.group(70, "0")
+			for _, g := range e.groups {
+				d.group(g.code, g.value)
+			}
+		}
+		d.group(0, "ENDTAB")
+		return handles
+	}
+
+	lineType := func(name, description string) entry {
+		return entry{name, []group{{3, description}, {72, "65"}, {73, "0"}, {40, "0"}}}
+	}
+	layerEntry := func(name string) entry {
+		return entry{name, []group{{62, "7"}, {6, "Continuous"}}}
+	}
+
+	layers := []entry{layerEntry("0")}
+	if layer != "0" {
+		layers = append(layers, layerEntry(layer))
+	}
+
+	table("VPORT", "AcDbViewportTableRecord")
+	table("LTYPE", "AcDbLinetypeTableRecord",
+		lineType("ByBlock", ""), lineType("ByLayer", ""), lineType("Continuous", "Solid line"))
+	table("LAYER", "AcDbLayerTableRecord", layers...)
+	table("STYLE", "AcDbTextStyleTableRecord",
+		entry{"Standard", []group{{40, "0"}, {41, "1"}, {50, "0"}, {71, "0"}, {42, "2.5"}, {3, "txt"}, {4, ""}}})
+	table("VIEW", "AcDbViewTableRecord")
+	table("UCS", "AcDbUCSTableRecord")
+	table("APPID", "AcDbRegAppTableRecord", entry{"ACAD", nil})
+	table("DIMSTYLE", "AcDbDimStyleTableRecord", entry{"Standard", nil})
+	spaces := table("BLOCK_RECORD", "AcDbBlockTableRecord", entry{"*Model_Space", nil}, entry{"*Paper_Space", nil})
+	d.modelSpace, d.paperSpace = spaces[0], spaces[1]
+
+	d.group(0, "ENDSEC")
+}
+
+// writeBlocks writes the empty blocks of the model and paper space.
+func (d *dxfWriter) writeBlocks() {
+	d.section("BLOCKS")
+	for _, b := range []struct{ name, owner string }{
+		{"*Model_Space", d.modelSpace},
+		{"*Paper_Space", d.paperSpace},
+	} {
+		d.entity("BLOCK", b.owner, "0")
+		d.group(100, "AcDbBlockBegin")
+		d.group(2, b.name)
+		d.group(70, "0")
+		d.number(10, 0)
+		d.number(20, 0)
+		d.number(30, 0)
+		d.group(3, b.name)
+		d.group(1, "")
+
+		d.entity("ENDBLK", b.owner, "0")
+		d.group(100, "AcDbBlockEnd")
+	}
+	d.group(0, "ENDSEC")
+}
+
+// writeObjects writes the root dictionary, holding the dictionary of groups.
+func (d *dxfWriter) writeObjects() {
+	d.section("OBJECTS")
+
+	root, groups := d.next(), d.next()
+	d.group(0, "DICTIONARY")
+	d.group(5, root)
+	d.group(330, "0")
+	d.group(100, "AcDbDictionary")
+	d.group(281, "1")
+	d.group(3, "ACAD_GROUP")
+	d.group(350, groups)
+
+	d.group(0, "DICTIONARY")
+	d.group(5, groups)
+	d.group(330, root)
+	d.group(100, "AcDbDictionary")
+	d.group(281, "1")
+
+	d.group(0, "ENDSEC")
+}
@@ -0,0 +1,97 @@
+package qrcode
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestWriteDXF(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	m := q.Matrix(0)
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WriteDXF(&buf, DXFOptions{ModuleSize: 0.5, Layer: "QR"}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, len(lines)%2, 0)
+
+	type pair struct {
+		code  int
+		value string
+	}
+	var pairs []pair
+	for i := 0; i < len(lines); i += 2 {
+		code, err := strconv.Atoi(lines[i])
+		assert.NoError(t, err)
+		pairs = append(pairs, pair{code, lines[i+1]})
+	}
+	assert.Equal(t, pairs[len(pairs)-1], pair{0, "EOF"})
+	assert.True(t, strings.Contains(buf.String(), "$ACADVER\n1\nAC1015\n"))
+
+	var sections []string
+	for i, p := range pairs {
+		if p == (pair{0, "SECTION"}) {
+			sections = append(sections, pairs[i+1].value)
+		}
+	}
+	assert.Equal(t, sections, []string{"HEADER", "CLASSES", "TABLES", "BLOCKS", "ENTITIES", "OBJECTS"})
+
+	// Every object has a unique handle below the handle seed, and the
+	// entities belong to the model space block record.
+	var seed int64
+	handles := map[string]bool{}
+	modelSpace := ""
+	for i, p := range pairs {
+		switch {
+		case p == (pair{9, "$HANDSEED"}):
+			seed, err = strconv.ParseInt(pairs[i+1].value, 16, 64)
+			assert.NoError(t, err)
+		case p.code == 5 && pairs[i-1].code == 0 || p.code == 105:
+			assert.False(t, handles[p.value])
+			handles[p.value] = true
+		case p == (pair{2, "*Model_Space"}) && pairs[i-5] == (pair{0, "BLOCK_RECORD"}):
+			modelSpace = pairs[i-4].value
+		}
+	}
+	for h := range handles {
+		v, err := strconv.ParseInt(h, 16, 64)
+		assert.NoError(t, err)
+		assert.True(t, v > 0 && v < seed)
+	}
+	assert.NotEqual(t, modelSpace, "")
+
+	contours := traceContours(m.dark)
+	polylines := 0
+	for i, p := range pairs {
+		if p != (pair{0, "LWPOLYLINE"}) {
+			continue
+		}
+		c := contours[polylines]
+		polylines++
+
+		assert.Equal(t, pairs[i+1].code, 5)
+		assert.Equal(t, pairs[i+2], pair{330, modelSpace})
+		assert.Equal(t, pairs[i+3], pair{100, "AcDbEntity"})
+		assert.Equal(t, pairs[i+4], pair{8, "QR"})
+		assert.Equal(t, pairs[i+5], pair{100, "AcDbPolyline"})
+		assert.Equal(t, pairs[i+6], pair{90, strconv.Itoa(len(c.points))})
+		assert.Equal(t, pairs[i+7], pair{70, "1"})
+
+		// Y points up in millimetres.
+		x, _ := strconv.ParseFloat(pairs[i+8].value, 64)
+		y, _ := strconv.ParseFloat(pairs[i+9].value, 64)
+		assert.Equal(t, x, c.points[0].x*0.5)
+		assert.Equal(t, y, (float64(m.size)-c.points[0].y)*0.5)
+	}
+	assert.Equal(t, polylines, len(contours))
+
+	assert.Error(t, q.WriteDXF(&buf, DXFOptions{ModuleSize: -1}))
+	for _, layer := range []string{"a\nb", "a\x00b", "a/b", "a=b"} {
+		assert.Error(t, q.WriteDXF(&buf, DXFOptions{Layer: layer}))
+	}
+}
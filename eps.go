@@ -0,0 +1,214 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+)
+
+// DefaultEPSModuleSize is the default size of a module in EPS output, in
+// points.
+const DefaultEPSModuleSize = 2
+
+// EPSOptions configures the Encapsulated PostScript output of a QRCode.
+type EPSOptions struct {
+	// ModuleSize is the size of a module in points. Defaults to
+	// DefaultEPSModuleSize.
+	ModuleSize float64
+
+	// QuietZone is the width of the quiet zone in modules. Zero keeps the
+	// default quiet zone and NoQuietZone removes it.
+	QuietZone int
+
+	// CMYK writes the colors as DeviceCMYK instead of DeviceRGB.
+	CMYK bool
+
+	// SpotColor, when set, paints the dark modules and the finder
+	// patterns with a Separation color space of that name. The foreground
+	// color is used as its CMYK alternate.
+	SpotColor string
+}
+
+// WriteEPS writes an Encapsulated PostScript image of the QRCode.
+//
+// PostScript has no transparency: partially transparent colors are written
+// as they appear over the background on a white page, and fully transparent
+// colors are not painted.
+func (q *QRCode) WriteEPS(out io.Writer, opts EPSOptions) error {
+	if opts.ModuleSize == 0 {
+		opts.ModuleSize = DefaultEPSModuleSize
+	}
+	if opts.ModuleSize < 0 {
+		return errors.New("invalid EPS module size")
+	}
+	if strings.ContainsAny(opts.SpotColor, "()\\") {
+		return fmt.Errorf("invalid EPS spot color name %q", opts.SpotColor)
+	}
+	if err := q.checkColors(); err != nil {
+		return err
+	}
+
+	m := q.Matrix(opts.QuietZone)
+	frame := q.canvas(m)
+	width, height := frame.w*opts.ModuleSize, frame.h*opts.ModuleSize
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(buf, "%%%%BoundingBox: 0 0 %d %d\n", int(math.Ceil(width)), int(math.Ceil(height)))
+	fmt.Fprintf(buf, "%%%%HiResBoundingBox: 0 0 %s %s\n", formatNumber(width), formatNumber(height))
+	buf.WriteString("%%Creator: github.com/i9si-sistemas/qrcode\n")
+	if q.foregroundFill(m).gradient != nil && opts.SpotColor == "" {
+		buf.WriteString("%%LanguageLevel: 3\n")
+	} else {
+		buf.WriteString("%%LanguageLevel: 2\n")
+	}
+	buf.WriteString("%%Pages: 1\n")
+
+	foreground := color.CMYKModel.Convert(q.ForegroundColor).(color.CMYK)
+	if opts.SpotColor != "" {
+		fmt.Fprintf(buf, "%%%%DocumentCustomColors: (%s)\n", opts.SpotColor)
+		fmt.Fprintf(buf, "%%%%CMYKCustomColor: %s (%s)\n", epsCMYK(foreground), opts.SpotColor)
+	} else if opts.CMYK {
+		buf.WriteString("%%DocumentProcessColors: Cyan Magenta Yellow Black\n")
+	}
+	buf.WriteString("%%EndComments\n")
+
+	buf.WriteString("%%Page: 1 1\n")
+	buf.WriteString("gsave 1 dict begin\n")
+	buf.WriteString("/r { 4 2 roll moveto 1 index 0 rlineto 0 exch rlineto neg 0 rlineto closepath } bind def\n")
+	fmt.Fprintf(buf, "%s %s translate %s %s scale\n",
+		formatNumber(-frame.x*opts.ModuleSize), formatNumber(height+frame.y*opts.ModuleSize),
+		formatNumber(opts.ModuleSize), formatNumber(-opts.ModuleSize))
+
+	cmyk := opts.CMYK || opts.SpotColor != ""
+	_, page := q.pageColors()
+	if fill, ok := epsFillColor(q.BackgroundColor, toNRGBA(color.White), cmyk); ok {
+		fmt.Fprintf(buf, "%s\n%s %s %s %s r fill\n", fill,
+			formatNumber(frame.x), formatNumber(frame.y), formatNumber(frame.w), formatNumber(frame.h))
+	}
+
+	q.drawVector(epsPainter{buf, opts, foreground, page}, m)
+
+	// The frame keeps its own colors rather than the spot color.
+	frameOpts := opts
+	frameOpts.SpotColor, frameOpts.CMYK = "", cmyk
+	paintFrame(epsPainter{buf, frameOpts, foreground, page}, frame)
+
+	buf.WriteString("end grestore\n")
+	buf.WriteString("showpage\n")
+	buf.WriteString("%%EOF\n")
+
+	if _, err := out.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return nil
+}
+
+type epsPainter struct {
+	buf        *bytes.Buffer
+	opts       EPSOptions
+	foreground color.CMYK
+
+	// page is the background as it appears on a white page.
+	page color.NRGBA
+}
+
+func (v epsPainter) paint(f fill, evenOdd bool, draw func(p pathWriter)) {
+	switch {
+	case v.opts.SpotColor != "":
+		if !f.visible() {
+			return
+		}
+		cyan, magenta, yellow, black := epsComponents(v.foreground)
+		fmt.Fprintf(v.buf, "[/Separation (%s) /DeviceCMYK { dup %s mul exch dup %s mul exch dup %s mul exch %s mul }] setcolorspace 1 setcolor\n",
+			v.opts.SpotColor, cyan, magenta, yellow, black)
+	case f.gradient != nil:
+		v.buf.WriteString("gsave\n")
+		draw(epsPath{v.buf})
+		if evenOdd {
+			v.buf.WriteString("eoclip newpath\n")
+		} else {
+			v.buf.WriteString("clip newpath\n")
+		}
+		fmt.Fprintf(v.buf, "%s shfill\ngrestore\n", shadingDict(f))
+		return
+	default:
+		setColor, ok := epsFillColor(f.color, v.page, v.opts.CMYK)
+		if !ok {
+			return
+		}
+		v.buf.WriteString(setColor + "\n")
+	}
+
+	draw(epsPath{v.buf})
+	if evenOdd {
+		v.buf.WriteString("eofill\n")
+	} else {
+		v.buf.WriteString("fill\n")
+	}
+}
+
+type epsPath struct {
+	buf *bytes.Buffer
+}
+
+func (p epsPath) moveTo(x, y float64) {
+	fmt.Fprintf(p.buf, "%s %s moveto\n", formatNumber(x), formatNumber(y))
+}
+
+func (p epsPath) lineTo(x, y float64) {
+	fmt.Fprintf(p.buf, "%s %s lineto\n", formatNumber(x), formatNumber(y))
+}
+
+func (p epsPath) curveTo(x1, y1, x2, y2, x, y float64) {
+	fmt.Fprintf(p.buf, "%s %s %s %s %s %s curveto\n",
+		formatNumber(x1), formatNumber(y1),
+		formatNumber(x2), formatNumber(y2),
+		formatNumber(x), formatNumber(y))
+}
+
+func (p epsPath) closePath() {
+	p.buf.WriteString("closepath\n")
+}
+
+func (p epsPath) rect(x, y, w, h int) {
+	fmt.Fprintf(p.buf, "%d %d %d %d r\n", x, y, w, h)
+}
+
+// epsFillColor returns the PostScript operators setting c, drawn over page,
+// as the fill color. It returns false when c is fully transparent.
+func epsFillColor(c color.Color, page color.NRGBA, cmyk bool) (string, bool) {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if n.A == 0 {
+		return "", false
+	}
+	if n.A != 0xff {
+		n = over(n, page)
+		c = n
+	}
+
+	if cmyk {
+		return epsCMYK(color.CMYKModel.Convert(c).(color.CMYK)) + " setcmykcolor", true
+	}
+
+	return fmt.Sprintf("%s %s %s setrgbcolor",
+		formatNumber(float64(n.R)/0xff),
+		formatNumber(float64(n.G)/0xff),
+		formatNumber(float64(n.B)/0xff)), true
+}
+
+func epsCMYK(c color.CMYK) string {
+	cyan, magenta, yellow, black := epsComponents(c)
+	return strings.Join([]string{cyan, magenta, yellow, black}, " ")
+}
+
+func epsComponents(c color.CMYK) (cyan, magenta, yellow, black string) {
+	return formatNumber(float64(c.C) / 0xff),
+		formatNumber(float64(c.M) / 0xff),
+		formatNumber(float64(c.Y) / 0xff),
+		formatNumber(float64(c.K) / 0xff)
+}
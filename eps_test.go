@@ -0,0 +1,75 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestWriteEPS(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, q.WriteEPS(buf, EPSOptions{ModuleSize: 1.5, QuietZone: 2}))
+
+	eps := buf.String()
+	modules := q.VersionNumber*4 + 17 + 2*2
+	size := formatNumber(float64(modules) * 1.5)
+
+	assert.True(t, strings.HasPrefix(eps, "%!PS-Adobe-3.0 EPSF-3.0\n"))
+	assert.True(t, strings.Contains(eps, "%%HiResBoundingBox: 0 0 "+size+" "+size+"\n"))
+	assert.True(t, strings.Contains(eps, "0 0 0 setrgbcolor"))
+	assert.True(t, strings.HasSuffix(eps, "%%EOF\n"))
+}
+
+func TestWriteEPSSpotColor(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	q.WithColors(color.CMYK{C: 0xff, K: 0x80}, color.Transparent)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, q.WriteEPS(buf, EPSOptions{SpotColor: "PANTONE 300 C", QuietZone: NoQuietZone}))
+
+	eps := buf.String()
+	assert.True(t, strings.Contains(eps, "%%DocumentCustomColors: (PANTONE 300 C)"))
+	assert.True(t, strings.Contains(eps, "[/Separation (PANTONE 300 C) /DeviceCMYK"))
+	assert.False(t, strings.Contains(eps, "setrgbcolor"))
+
+	assert.Error(t, q.WriteEPS(buf, EPSOptions{SpotColor: "bad)name"}))
+}
+
+func TestBitmapWithQuietZone(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	bitmap := q.Bitmap()
+	none := q.bitmapWithQuietZone(NoQuietZone)
+	assert.Equal(t, len(none), len(bitmap)-8)
+	assert.Equal(t, len(q.bitmapWithQuietZone(1)), len(none)+2)
+
+	for y := range none {
+		for x := range none {
+			assert.Equal(t, none[y][x], bitmap[y+4][x+4])
+		}
+	}
+}
+
+func TestWriteEPSTransparency(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	// Half transparent black modules are grey on the white background.
+	q.WithColors(color.NRGBA{0, 0, 0, 0x80}, color.White)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, q.WriteEPS(buf, EPSOptions{}))
+	eps := buf.String()
+	assert.True(t, strings.Contains(eps, "1 1 1 setrgbcolor"))
+	assert.True(t, strings.Contains(eps, "0.498 0.498 0.498 setrgbcolor"))
+	assert.False(t, strings.Contains(eps, "0 0 0 setrgbcolor"))
+}
@@ -0,0 +1,274 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+)
+
+// GradientKind is the geometry of a Gradient.
+type GradientKind int
+
+const (
+	LinearGradient GradientKind = iota
+	RadialGradient
+)
+
+// GradientStop is a color at an offset, from 0 to 1, along a Gradient.
+type GradientStop struct {
+	Offset float64
+	Color  color.Color
+}
+
+// Gradient is a foreground fill spanning the symbol, without its quiet zone.
+type Gradient struct {
+	Kind GradientKind
+
+	// Angle is the direction of a linear gradient in degrees, clockwise
+	// from left to right.
+	Angle float64
+
+	// Stops are the colors of the gradient. Radial gradients go from the
+	// centre of the symbol to its corners.
+	Stops []GradientStop
+}
+
+// minStyleContrast is the minimum contrast ratio between the colors used
+// to draw a QRCode and its background.
+const minStyleContrast = 3
+
+// Validate checks that the gradient has at least two stops, and that every
+// stop is dark enough against background for the symbol to be read.
+// Transparent colors are checked over a white page.
+func (g *Gradient) Validate(background color.Color) error {
+	if len(g.Stops) < 2 {
+		return errors.New("gradient needs at least two stops")
+	}
+
+	page := onWhite(background)
+	for i, s := range g.Stops {
+		if s.Offset < 0 || s.Offset > 1 {
+			return fmt.Errorf("gradient stop %d has offset %g (expected 0-1 inclusive)", i, s.Offset)
+		}
+		if s.Color == nil {
+			return fmt.Errorf("gradient stop %d has no color", i)
+		}
+		if ratio := ContrastRatio(over(toNRGBA(s.Color), page), page); ratio < minStyleContrast {
+			return fmt.Errorf("gradient stop %d has contrast ratio %.2f against the background (minimum is %d)",
+				i, ratio, minStyleContrast)
+		}
+	}
+
+	return nil
+}
+
+// stops returns the stops of g sorted by offset, with stops added at 0 and 1
+// if needed.
+func (g *Gradient) stops() []GradientStop {
+	stops := slices.Clone(g.Stops)
+	slices.SortStableFunc(stops, func(a, b GradientStop) int {
+		switch {
+		case a.Offset < b.Offset:
+			return -1
+		case a.Offset > b.Offset:
+			return 1
+		}
+		return 0
+	})
+
+	if stops[0].Offset > 0 {
+		stops = append([]GradientStop{{0, stops[0].Color}}, stops...)
+	}
+	if last := stops[len(stops)-1]; last.Offset < 1 {
+		stops = append(stops, GradientStop{1, last.Color})
+	}
+
+	return stops
+}
+
+// gradientColor returns the color at offset t of a gradient with the given
+// stops, as returned by Gradient.stops.
+func gradientColor(stops []GradientStop, t float64) color.NRGBA {
+	t = max(0, min(1, t))
+
+	for i := 1; i < len(stops); i++ {
+		a, b := stops[i-1], stops[i]
+		if t > b.Offset {
+			continue
+		}
+
+		span := b.Offset - a.Offset
+		if span == 0 {
+			return toNRGBA(b.Color)
+		}
+
+		k := (t - a.Offset) / span
+		var sum colorSum
+		sum.addWeighted(toNRGBA(a.Color), 1-k)
+		sum.addWeighted(toNRGBA(b.Color), k)
+		return sum.average()
+	}
+
+	return toNRGBA(stops[len(stops)-1].Color)
+}
+
+// SetGradient sets the gradient used for the foreground of the QRCode. It
+// returns an error, leaving the QRCode unchanged, if the gradient does not
+// have enough contrast against the background. A nil gradient restores the
+// solid foreground color.
+func (q *QRCode) SetGradient(g *Gradient) error {
+	if g != nil {
+		if err := g.Validate(q.BackgroundColor); err != nil {
+			return err
+		}
+	}
+
+	q.Gradient = g
+	return nil
+}
+
+// WithRegionColors sets separate colors for the data modules, the finder
+// patterns and the alignment patterns. A nil color uses the foreground, as
+// does a color that cannot be read against the background, which CheckColors
+// reports with ErrColorIgnored.
+func (q *QRCode) WithRegionColors(data, finder, alignment color.Color) *QRCode {
+	q.DataColor = data
+	q.FinderColor = finder
+	q.AlignmentColor = alignment
+	return q
+}
+
+// fill is a solid color or a gradient used to paint modules.
+type fill struct {
+	color    color.Color
+	gradient *Gradient
+	stops    []GradientStop
+
+	// x, y and size locate the square spanned by the gradient, in modules.
+	x, y, size float64
+}
+
+// at returns the color of f at x, y in module coordinates.
+func (f fill) at(x, y float64) color.NRGBA {
+	if f.gradient == nil {
+		return toNRGBA(f.color)
+	}
+	return gradientColor(f.stops, f.offset(x, y))
+}
+
+func (f fill) equal(other fill) bool {
+	return sameColor(f.color, other.color) && f.gradient == other.gradient
+}
+
+// offset returns the position of x, y along the gradient of f.
+func (f fill) offset(x, y float64) float64 {
+	if f.gradient.Kind == RadialGradient {
+		cx, cy, r := f.radialCoords()
+		return math.Hypot(x-cx, y-cy) / r
+	}
+
+	x0, y0, x1, y1 := f.linearCoords()
+	dx, dy := x1-x0, y1-y0
+	return ((x-x0)*dx + (y-y0)*dy) / (dx*dx + dy*dy)
+}
+
+// linearCoords returns the start and end points of a linear gradient, so
+// that the corners of the square are at offsets 0 and 1.
+func (f fill) linearCoords() (x0, y0, x1, y1 float64) {
+	angle := f.gradient.Angle * math.Pi / 180
+	dx, dy := math.Cos(angle), math.Sin(angle)
+	half := (math.Abs(dx) + math.Abs(dy)) * f.size / 2
+	cx, cy := f.x+f.size/2, f.y+f.size/2
+
+	return cx - dx*half, cy - dy*half, cx + dx*half, cy + dy*half
+}
+
+// radialCoords returns the centre and radius of a radial gradient.
+func (f fill) radialCoords() (cx, cy, r float64) {
+	return f.x + f.size/2, f.y + f.size/2, f.size / math.Sqrt2
+}
+
+// visible reports whether painting with f has any effect.
+func (f fill) visible() bool {
+	if f.gradient != nil {
+		return true
+	}
+	return toNRGBA(f.color).A != 0
+}
+
+// foregroundFill returns the fill of the foreground of the QRCode. A gradient
+// without enough contrast is replaced by the foreground color, which
+// CheckColors reports with ErrColorIgnored.
+func (q *QRCode) foregroundFill(m *ModuleMatrix) fill {
+	f := fill{
+		color: q.ForegroundColor,
+		x:     float64(m.quietZone),
+		y:     float64(m.quietZone),
+		size:  float64(m.size - 2*m.quietZone),
+	}
+
+	if q.Gradient != nil && q.Gradient.Validate(q.BackgroundColor) == nil {
+		f.gradient = q.Gradient
+		f.stops = q.Gradient.stops()
+	}
+
+	return f
+}
+
+// styleFill returns a fill of color c, or the foreground fill if c is nil or
+// cannot be read against the background, which CheckColors reports with
+// ErrColorIgnored.
+func (q *QRCode) styleFill(m *ModuleMatrix, c color.Color) fill {
+	foreground := q.foregroundFill(m)
+	if c == nil || q.styleColorError(c) != nil {
+		return foreground
+	}
+
+	foreground.color = c
+	foreground.gradient = nil
+	foreground.stops = nil
+	return foreground
+}
+
+// regionFill returns the fill of modules with the given role.
+func (q *QRCode) regionFill(m *ModuleMatrix, role ModuleRole) fill {
+	switch role {
+	case RoleFinder:
+		return q.styleFill(m, q.FinderColor)
+	case RoleAlignment:
+		return q.styleFill(m, q.AlignmentColor)
+	default:
+		return q.styleFill(m, q.DataColor)
+	}
+}
+
+// layer is a set of module roles painted with the same fill.
+type layer struct {
+	fill  fill
+	roles []ModuleRole
+}
+
+// layers groups the roles of the dark modules by fill. Finder patterns are
+// left out when they are drawn as styled eyes.
+func (q *QRCode) layers(m *ModuleMatrix) []layer {
+	roles := []ModuleRole{RoleData, RoleTiming, RoleFormat, RoleVersion, RoleAlignment}
+	if q.FinderStyle.isDefault() {
+		roles = append(roles, RoleFinder)
+	}
+
+	var layers []layer
+	for _, role := range roles {
+		f := q.regionFill(m, role)
+
+		i := slices.IndexFunc(layers, func(l layer) bool { return l.fill.equal(f) })
+		if i < 0 {
+			layers = append(layers, layer{fill: f})
+			i = len(layers) - 1
+		}
+		layers[i].roles = append(layers[i].roles, role)
+	}
+
+	return layers
+}
@@ -0,0 +1,191 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+var blueToRed = &Gradient{
+	Kind: LinearGradient,
+	Stops: []GradientStop{
+		{0, color.RGBA{0x00, 0x30, 0x90, 0xff}},
+		{1, color.RGBA{0x90, 0x00, 0x30, 0xff}},
+	},
+}
+
+func TestGradientValidate(t *testing.T) {
+	assert.NoError(t, blueToRed.Validate(color.White))
+	assert.Error(t, blueToRed.Validate(color.Black))
+
+	light := &Gradient{Stops: []GradientStop{
+		{0, color.Black},
+		{1, color.RGBA{0xd0, 0xd0, 0xd0, 0xff}},
+	}}
+	assert.Error(t, light.Validate(color.White))
+	assert.Error(t, (&Gradient{Stops: []GradientStop{{0, color.Black}}}).Validate(color.White))
+
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	assert.Error(t, q.SetGradient(light))
+	assert.True(t, q.Gradient == nil)
+	assert.NoError(t, q.SetGradient(blueToRed))
+	assert.True(t, q.Gradient == blueToRed)
+}
+
+func TestStyleColorsOnTransparentBackground(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithColors(color.Black, color.Transparent)
+	m := q.Matrix(0)
+
+	// A transparent background is checked over a white page, not as black.
+	navyToBlack := &Gradient{Stops: []GradientStop{{0, navy}, {1, color.Black}}}
+	assert.NoError(t, q.SetGradient(navyToBlack))
+	q.WithRegionColors(nil, crimson, nil)
+	assert.Equal(t, q.regionFill(m, RoleFinder).color, color.Color(crimson))
+	assert.NoError(t, q.CheckColors())
+}
+
+func TestStyleColorsIgnored(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	m := q.Matrix(0)
+
+	var warnings []error
+	q.OnWarning = func(err error) { warnings = append(warnings, err) }
+
+	q.WithRegionColors(lightGrey, nil, nil)
+	assert.Equal(t, q.regionFill(m, RoleData).color, q.ForegroundColor)
+	assert.True(t, errors.Is(q.CheckColors(), ErrColorIgnored))
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WriteSVG(&buf, SVGOptions{}))
+	assert.Equal(t, len(warnings), 1)
+	assert.True(t, errors.Is(warnings[0], ErrColorIgnored))
+
+	q.WithRegionColors(nil, nil, nil)
+	q.FinderStyle.InnerColors[2] = color.White
+	assert.True(t, errors.Is(q.CheckColors(), ErrColorIgnored))
+	q.FinderStyle = FinderStyle{}
+
+	q.Gradient = &Gradient{Stops: []GradientStop{{0, color.Black}, {1, lightGrey}}}
+	assert.True(t, errors.Is(q.CheckColors(), ErrColorIgnored))
+}
+
+func TestGradientColor(t *testing.T) {
+	stops := (&Gradient{Stops: []GradientStop{
+		{0.5, color.RGBA{0, 0, 0, 0xff}},
+		{1, color.RGBA{0xff, 0, 0, 0xff}},
+	}}).stops()
+
+	assert.Equal(t, len(stops), 3)
+	assert.Equal(t, gradientColor(stops, 0.25), color.NRGBA{0, 0, 0, 0xff})
+	assert.Equal(t, gradientColor(stops, 0.75), color.NRGBA{0x80, 0, 0, 0xff})
+	assert.Equal(t, gradientColor(stops, 2), color.NRGBA{0xff, 0, 0, 0xff})
+}
+
+func TestFillOffset(t *testing.T) {
+	for _, angle := range []float64{0, 45, 90, 200} {
+		f := fill{gradient: &Gradient{Angle: angle}, x: 4, y: 4, size: 10}
+
+		var lo, hi float64 = 1, 0
+		for _, p := range []point{{4, 4}, {14, 4}, {4, 14}, {14, 14}} {
+			o := f.offset(p.x, p.y)
+			lo, hi = min(lo, o), max(hi, o)
+		}
+		assert.True(t, lo > -1e-9 && lo < 1e-9)
+		assert.True(t, hi > 1-1e-9 && hi < 1+1e-9)
+	}
+
+	radial := fill{gradient: &Gradient{Kind: RadialGradient}, x: 4, y: 4, size: 10}
+	assert.Equal(t, radial.offset(9, 9), 0.0)
+	assert.True(t, radial.offset(14, 14) > 1-1e-9)
+}
+
+func TestGradientImage(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	assert.NoError(t, q.SetGradient(blueToRed))
+
+	img, ok := q.Image(-4).(*image.NRGBA)
+	assert.True(t, ok)
+
+	first := img.NRGBAAt(4*4+1, 4*4+1)
+	last := img.NRGBAAt(img.Bounds().Dx()-4*4-2, 4*4+1)
+	assert.True(t, first.B > first.R)
+	assert.True(t, last.R > last.B)
+}
+
+func TestRegionColors(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(q.layers(q.Matrix(0))), 1)
+
+	q.WithRegionColors(nil, crimson, navy)
+	assert.Equal(t, len(q.layers(q.Matrix(0))), 3)
+
+	q.WithRegionColors(nil, lightGrey, nil)
+	assert.Equal(t, len(q.layers(q.Matrix(0))), 1)
+
+	q.WithRegionColors(navy, crimson, nil)
+	img, ok := q.Image(-1).(*image.NRGBA)
+	assert.True(t, ok)
+	assert.Equal(t, img.NRGBAAt(4, 4), toNRGBA(crimson))
+}
+
+func TestFillEqual(t *testing.T) {
+	assert.True(t, fill{}.equal(fill{}))
+	assert.False(t, fill{color: navy}.equal(fill{}))
+	assert.True(t, fill{color: navy}.equal(fill{color: toNRGBA(navy)}))
+	assert.False(t, fill{color: navy}.equal(fill{color: navy, gradient: blueToRed}))
+
+	a, b := uncomparableColor{c: navy}, uncomparableColor{c: navy}
+	assert.True(t, fill{color: a}.equal(fill{color: b}))
+	assert.False(t, fill{color: a}.equal(fill{color: crimson}))
+
+	// Colors that cannot be compared with == are drawn.
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithRegionColors(a, uncomparableColor{c: crimson}, b)
+	assert.Equal(t, len(q.layers(q.Matrix(0))), 2)
+	assert.NotNil(t, q.Image(128))
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WriteSVG(&buf, SVGOptions{}))
+}
+
+func TestGradientVector(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	assert.NoError(t, q.SetGradient(blueToRed))
+	q.WithRegionColors(nil, crimson, nil)
+
+	svg := string(q.SVG(SVGOptions{}))
+	assert.True(t, strings.Contains(svg, `<linearGradient id="qrcode-gradient"`))
+	assert.True(t, strings.Contains(svg, `fill="url(#qrcode-gradient)"`))
+
+	// Documents inlined in the same page use their own ids.
+	svg = string(q.SVG(SVGOptions{IDPrefix: "second-", Title: "Second"}))
+	assert.True(t, strings.Contains(svg, `<linearGradient id="second-gradient"`))
+	assert.True(t, strings.Contains(svg, `fill="url(#second-gradient)"`))
+	assert.True(t, strings.Contains(svg, `aria-labelledby="second-title"`))
+	assert.False(t, strings.Contains(svg, "qrcode-"))
+	assert.True(t, strings.Contains(svg, `fill="#a01020"`))
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, q.WritePDF(buf, PDFOptions{}))
+	assert.True(t, strings.Contains(buf.String(), "/Shading << /Sh0 << /ShadingType 2"))
+	assert.True(t, strings.Contains(buf.String(), "W n\n/Sh0 sh\nQ\n"))
+
+	buf.Reset()
+	assert.NoError(t, q.WriteEPS(buf, EPSOptions{}))
+	assert.True(t, strings.Contains(buf.String(), "%%LanguageLevel: 3"))
+	assert.True(t, strings.Contains(buf.String(), "shfill"))
+}
@@ -0,0 +1,154 @@
+package qrcode
+
+import (
+	"image/color"
+)
+
+// EyeShape is the shape of a part of a finder pattern.
+type EyeShape int
+
+const (
+	EyeSquare EyeShape = iota
+	EyeRounded
+	EyeCircle
+	// EyeLeaf rounds two opposite corners, leaving the corners on the
+	// diagonal through the centre of the symbol sharp.
+	EyeLeaf
+)
+
+// FinderStyle styles the three 7x7 finder patterns ("eyes") of a QRCode.
+//
+// The outer ring and the inner 3x3 square keep their widths along the axes
+// of the pattern, so the 1:1:3:1:1 proportions readers look for are
+// preserved whatever the shape.
+type FinderStyle struct {
+	// Outer is the shape of the 7x7 ring.
+	Outer EyeShape
+	// Inner is the shape of the 3x3 centre.
+	Inner EyeShape
+
+	// OuterColors and InnerColors set the colors of the top-left,
+	// top-right and bottom-left eyes. A nil color, or a color without
+	// enough contrast against the background, uses the finder pattern
+	// color of the QRCode. CheckColors reports the colors replaced.
+	OuterColors [3]color.Color
+	InnerColors [3]color.Color
+}
+
+// isDefault reports whether s draws plain square finder patterns. The colors
+// are compared with nil, as a color.Color may not be comparable.
+func (s FinderStyle) isDefault() bool {
+	if s.Outer != EyeSquare || s.Inner != EyeSquare {
+		return false
+	}
+	for i := range s.OuterColors {
+		if s.OuterColors[i] != nil || s.InnerColors[i] != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// eye is a styled finder pattern in module coordinates.
+type eye struct {
+	outer, hole, inner   roundedRect
+	outerFill, innerFill fill
+}
+
+// fillAt returns the fill of the eye at x, y and whether the point lies
+// within the 7x7 pattern. The fill is nil for the light ring.
+func (e *eye) fillAt(x, y float64) (*fill, bool) {
+	box := e.outer
+	if x < box.x || y < box.y || x >= box.x+box.w || y >= box.y+box.h {
+		return nil, false
+	}
+
+	switch {
+	case e.inner.contains(x, y):
+		return &e.innerFill, true
+	case e.outer.contains(x, y) && !e.hole.contains(x, y):
+		return &e.outerFill, true
+	default:
+		return nil, true
+	}
+}
+
+// eyes returns the styled finder patterns of the QRCode, in the order
+// top-left, top-right, bottom-left. It returns nil when the finder patterns
+// are not styled.
+func (q *QRCode) eyes(m *ModuleMatrix) []eye {
+	if q.FinderStyle.isDefault() {
+		return nil
+	}
+
+	s := q.FinderStyle
+	qz := m.quietZone
+	far := m.size - qz - finderPatternSize
+
+	origins := [3]point{
+		{float64(qz), float64(qz)},
+		{float64(far), float64(qz)},
+		{float64(qz), float64(far)},
+	}
+
+	// The corners on the diagonal through the centre of the symbol, which
+	// stay sharp for EyeLeaf, clockwise from the top-left.
+	diagonals := [3][4]bool{
+		{true, false, true, false},
+		{false, true, false, true},
+		{false, true, false, true},
+	}
+
+	eyes := make([]eye, len(origins))
+	for i, o := range origins {
+		eyes[i] = eye{
+			outer:     eyeRect(s.Outer, o.x, o.y, 7, diagonals[i]),
+			hole:      eyeRect(s.Outer, o.x+1, o.y+1, 5, diagonals[i]),
+			inner:     eyeRect(s.Inner, o.x+2, o.y+2, 3, diagonals[i]),
+			outerFill: q.finderFill(m, s.OuterColors[i]),
+			innerFill: q.finderFill(m, s.InnerColors[i]),
+		}
+	}
+
+	return eyes
+}
+
+func eyeRect(shape EyeShape, x, y, size float64, sharp [4]bool) roundedRect {
+	var r [4]float64
+
+	for i := range r {
+		switch shape {
+		case EyeRounded:
+			r[i] = size / 4
+		case EyeCircle:
+			r[i] = size / 2
+		case EyeLeaf:
+			if !sharp[i] {
+				r[i] = size / 2
+			}
+		}
+	}
+
+	return roundedRect{x, y, size, size, r}
+}
+
+// finderFill returns the fill of a part of a styled eye of color c.
+func (q *QRCode) finderFill(m *ModuleMatrix, c color.Color) fill {
+	if c == nil || q.styleColorError(c) != nil {
+		return q.regionFill(m, RoleFinder)
+	}
+	return q.styleFill(m, c)
+}
+
+// writeRing writes the outline of the ring of e to p. The ring must be
+// filled with the even-odd rule.
+func (e eye) writeRing(p pathWriter) {
+	e.outer.path(p)
+	e.hole.path(p)
+}
+
+// WithFinderStyle sets the style of the finder patterns of the QRCode.
+func (q *QRCode) WithFinderStyle(style FinderStyle) *QRCode {
+	q.FinderStyle = style
+	return q
+}
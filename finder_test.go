@@ -0,0 +1,105 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+var (
+	navy      = color.RGBA{0x00, 0x20, 0x60, 0xff}
+	crimson   = color.RGBA{0xa0, 0x10, 0x20, 0xff}
+	lightGrey = color.RGBA{0xe0, 0xe0, 0xe0, 0xff}
+)
+
+// uncomparableColor is a color.Color that panics when compared with ==.
+type uncomparableColor struct {
+	c    color.RGBA
+	tags []string
+}
+
+func (u uncomparableColor) RGBA() (r, g, b, a uint32) {
+	return u.c.RGBA()
+}
+
+func TestFinderStyleIsDefault(t *testing.T) {
+	assert.True(t, FinderStyle{}.isDefault())
+	assert.False(t, FinderStyle{Inner: EyeCircle}.isDefault())
+
+	s := FinderStyle{OuterColors: [3]color.Color{nil, uncomparableColor{c: navy}, nil}}
+	assert.False(t, s.isDefault())
+
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.FinderStyle = s
+	assert.Equal(t, q.finderFill(q.Matrix(0), s.OuterColors[1]).color, s.OuterColors[1])
+	assert.NotNil(t, q.Image(128))
+}
+
+func TestFinderFill(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	m := q.Matrix(0)
+	finderColor := func(c color.Color) color.Color {
+		return q.finderFill(m, c).color
+	}
+
+	assert.Equal(t, finderColor(nil), q.ForegroundColor)
+	assert.Equal(t, finderColor(navy), color.Color(navy))
+	assert.Equal(t, finderColor(lightGrey), q.ForegroundColor)
+
+	q.WithRegionColors(nil, crimson, nil)
+	assert.Equal(t, finderColor(nil), color.Color(crimson))
+	assert.Equal(t, finderColor(lightGrey), color.Color(crimson))
+
+	q.WithColors(color.White, color.Black)
+	assert.Equal(t, finderColor(navy), q.ForegroundColor)
+}
+
+func TestFinderStyleImage(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	q.WithFinderStyle(FinderStyle{
+		Outer:       EyeLeaf,
+		Inner:       EyeCircle,
+		OuterColors: [3]color.Color{navy, nil, lightGrey},
+		InnerColors: [3]color.Color{crimson, crimson, crimson},
+	})
+
+	const moduleSize = 10
+	img, ok := q.Image(-moduleSize).(*image.NRGBA)
+	assert.True(t, ok)
+
+	at := func(x, y float64) color.NRGBA {
+		return img.NRGBAAt(int(x*moduleSize), int(y*moduleSize))
+	}
+
+	far := float64(len(q.Bitmap()) - 4 - 7)
+
+	assert.Equal(t, at(4.5, 7.5), toNRGBA(navy))
+	assert.Equal(t, at(7.5, 7.5), toNRGBA(crimson))
+	assert.Equal(t, at(5.5, 7.5), toNRGBA(color.White))
+	assert.Equal(t, at(far+0.5, 7.5), toNRGBA(color.Black))
+	assert.Equal(t, at(4.5, far+3.5), toNRGBA(color.Black))
+
+	assert.Equal(t, at(4.05, 4.05), toNRGBA(navy))
+	assert.Equal(t, at(10.95, 4.05), toNRGBA(color.White))
+}
+
+func TestFinderStyleSVG(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	plain := string(q.SVG(SVGOptions{}))
+	assert.True(t, strings.Contains(plain, "M4 4h7v1h-7z"))
+
+	styled := string(q.WithFinderStyle(FinderStyle{Outer: EyeRounded}).SVG(SVGOptions{}))
+	assert.False(t, strings.Contains(styled, "M4 4h7v1h-7z"))
+	assert.Equal(t, strings.Count(styled, `fill-rule="evenodd"`), 3)
+	assert.Equal(t, strings.Count(styled, "<path"), 7)
+}
@@ -0,0 +1,31 @@
+package qrcode
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestBitmapFont(t *testing.T) {
+	assert.Equal(t, BitmapFont.Advance("", 7), 0.0)
+	assert.Equal(t, BitmapFont.Advance("A", 7), 5.0)
+	assert.Equal(t, BitmapFont.Advance("AB", 14), 22.0)
+
+	text := newRasterShape(frameShape{color: color.Black, draw: func(p pathWriter) {
+		BitmapFont.Outline(pathAdapter{p}, "Q1", 0, 0, 7)
+	}})
+
+	for i, r := range "Q1" {
+		glyph := bitmapGlyph(r)
+		for row := range bitmapGlyphHeight {
+			for col := range bitmapGlyphWidth {
+				x := float64(i*bitmapAdvance+col) + 0.5
+				assert.Equal(t, text.contains(x, float64(row)+0.5), glyph[col]&(1<<row) != 0)
+			}
+		}
+	}
+
+	assert.Equal(t, bitmapGlyph('é'), bitmapGlyph('?'))
+	assert.Equal(t, bitmapGlyph('\n'), bitmapGlyph('?'))
+}
@@ -0,0 +1,175 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FormatOptions configures the raster output of WriteFormat.
+type FormatOptions struct {
+	// Size is the width of the image in pixels, as for Image.
+	Size int
+
+	// Quality is the JPEG quality, from 1 to 100. Zero uses the default
+	// quality of image/jpeg.
+	Quality int
+}
+
+// ImageEncoder writes img in an image format.
+type ImageEncoder func(w io.Writer, img image.Image, opts FormatOptions) error
+
+type imageFormat struct {
+	name       string
+	extensions []string
+	encode     ImageEncoder
+
+	// write, when set, writes the QRCode without drawing a whole image
+	// first.
+	write func(q *QRCode, w io.Writer, opts FormatOptions) error
+}
+
+var (
+	formatsMu sync.RWMutex
+	formats   []imageFormat
+)
+
+func init() {
+	registerFormat(imageFormat{
+		name:       "png",
+		extensions: []string{".png"},
+		encode:     encodePNG,
+		write: func(q *QRCode, w io.Writer, opts FormatOptions) error {
+			return q.writePNG(w, opts.Size)
+		},
+	})
+	RegisterFormat("jpeg", []string{".jpg", ".jpeg"}, encodeJPEG)
+	RegisterFormat("gif", []string{".gif"}, encodeGIF)
+	RegisterFormat("bmp", []string{".bmp"}, encodeBMP)
+	RegisterFormat("tiff", []string{".tif", ".tiff"}, encodeTIFF)
+}
+
+// RegisterFormat registers an image format used by WriteFormat, and by
+// WriteFile for files with one of the given extensions. Registering a name
+// again replaces the previous format.
+func RegisterFormat(name string, extensions []string, encode ImageEncoder) {
+	f := imageFormat{
+		name:   strings.ToLower(name),
+		encode: encode,
+	}
+	for _, ext := range extensions {
+		f.extensions = append(f.extensions, strings.ToLower(ext))
+	}
+
+	registerFormat(f)
+}
+
+func registerFormat(f imageFormat) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	i := slices.IndexFunc(formats, func(other imageFormat) bool { return other.name == f.name })
+	if i < 0 {
+		formats = append(formats, f)
+	} else {
+		formats[i] = f
+	}
+}
+
+// Formats returns the names of the registered image formats.
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.name
+	}
+	return names
+}
+
+func findFormat(match func(f imageFormat) bool) (imageFormat, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	i := slices.IndexFunc(formats, match)
+	if i < 0 {
+		return imageFormat{}, false
+	}
+	return formats[i], true
+}
+
+// formatForFile returns the format registered for the extension of filename,
+// or PNG.
+func formatForFile(filename string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	f, ok := findFormat(func(f imageFormat) bool { return slices.Contains(f.extensions, ext) })
+	if !ok {
+		return "png"
+	}
+	return f.name
+}
+
+// WriteFormat writes an image of the QRCode in the named format, such as
+// "png", "jpeg", "gif", "bmp" or "tiff".
+func (q *QRCode) WriteFormat(out io.Writer, format string, opts FormatOptions) error {
+	name := strings.ToLower(format)
+	f, ok := findFormat(func(f imageFormat) bool { return f.name == name })
+	if !ok {
+		return fmt.Errorf("unknown image format %q", format)
+	}
+	if err := q.checkColors(); err != nil {
+		return err
+	}
+
+	// The image is buffered so nothing is written if it fails.
+	buf := new(bytes.Buffer)
+	var err error
+	if f.write != nil {
+		err = f.write(q, buf, opts)
+	} else {
+		err = f.encode(buf, q.Image(opts.Size), opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(buf.Bytes())
+	return err
+}
+
+// writeFormatFile writes an image of the QRCode to filename, in the format
+// matching its extension.
+func (q *QRCode) writeFormatFile(filename string, opts FormatOptions) error {
+	buf := new(bytes.Buffer)
+	if err := q.WriteFormat(buf, formatForFile(filename), opts); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, buf.Bytes(), os.FileMode(0644))
+}
+
+func encodePNG(w io.Writer, img image.Image, _ FormatOptions) error {
+	encoder := png.Encoder{CompressionLevel: pngCompression(png.DefaultCompression)}
+	return encoder.Encode(w, img)
+}
+
+func encodeJPEG(w io.Writer, img image.Image, opts FormatOptions) error {
+	var o *jpeg.Options
+	if opts.Quality != 0 {
+		o = &jpeg.Options{Quality: opts.Quality}
+	}
+	return jpeg.Encode(w, img, o)
+}
+
+func encodeGIF(w io.Writer, img image.Image, _ FormatOptions) error {
+	return gif.Encode(w, img, nil)
+}
@@ -0,0 +1,60 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestWriteFormat(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WriteFormat(&buf, "JPEG", FormatOptions{Size: 100, Quality: 90}))
+	img, err := jpeg.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dx(), 100)
+
+	buf.Reset()
+	assert.NoError(t, q.WriteFormat(&buf, "gif", FormatOptions{Size: 100}))
+	img, err = gif.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dx(), 100)
+
+	assert.Error(t, q.WriteFormat(&buf, "webp", FormatOptions{}))
+}
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat("size", []string{".size"}, func(w io.Writer, img image.Image, _ FormatOptions) error {
+		_, err := w.Write([]byte{byte(img.Bounds().Dx())})
+		return err
+	})
+	assert.True(t, slices.Contains(Formats(), "size"))
+
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "qrcode.SIZE")
+	assert.NoError(t, q.WriteFile(-2, filename))
+	data, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Equal(t, data, []byte{byte(len(q.Bitmap()) * 2)})
+}
+
+func TestFormatForFile(t *testing.T) {
+	assert.Equal(t, formatForFile("qrcode.png"), "png")
+	assert.Equal(t, formatForFile("qrcode.JPG"), "jpeg")
+	assert.Equal(t, formatForFile("qrcode.tif"), "tiff")
+	assert.Equal(t, formatForFile("qrcode.bmp"), "bmp")
+	assert.Equal(t, formatForFile("qrcode"), "png")
+	assert.Equal(t, formatForFile("qrcode.unknown"), "png")
+}
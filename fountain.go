@@ -0,0 +1,437 @@
+package qrcode
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/gif"
+	"io"
+	"math"
+	"math/rand/v2"
+	"strings"
+)
+
+// DefaultFountainVersion is the default QR Code version of the frames of a
+// fountain coded transfer.
+const DefaultFountainVersion = 15
+
+// DefaultFountainModuleSize is the default width of a module in the frames of
+// an animated transfer, in pixels.
+const DefaultFountainModuleSize = 4
+
+// DefaultFountainFrameDelay is the default delay between the frames of an
+// animated transfer, in hundredths of a second.
+const DefaultFountainFrameDelay = 20
+
+// MaxFountainPayload is the largest payload of a fountain coded transfer, in
+// bytes. Decoders reject frames announcing a larger one.
+const MaxFountainPayload = 1 << 24
+
+// MaxFountainBlocks is the largest number of blocks of a fountain coded
+// transfer. Every frame draws a permutation of the blocks, so the limit keeps
+// the work per frame bounded.
+const MaxFountainBlocks = 1 << 16
+
+// fountainFormat identifies the layout of a fountain coded frame.
+const fountainFormat = 1
+
+// fountainHeaderLength is the length of the header of a frame: the format,
+// the payload length, the block size, the payload checksum and the seed.
+const fountainHeaderLength = 1 + 4 + 2 + 4 + 4
+
+// fountainEncoding encodes frames with characters of the alphanumeric mode,
+// which scanners return as text.
+var fountainEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// FountainOptions configures a fountain coded transfer.
+type FountainOptions struct {
+	// Version and Level set the QR Code of every frame. Version defaults
+	// to DefaultFountainVersion.
+	Version int
+	Level   RecoveryLevel
+
+	// Frames is the number of frames in an animation. It defaults to twice
+	// the number of blocks of the payload, so receivers can miss frames
+	// without waiting for the animation to loop.
+	Frames int
+
+	// ModuleSize is the width of a module in pixels. Defaults to
+	// DefaultFountainModuleSize.
+	ModuleSize int
+
+	// Delay is the delay between frames in hundredths of a second.
+	// Defaults to DefaultFountainFrameDelay.
+	Delay int
+}
+
+// FountainEncoder splits a payload into an endless stream of QR Code frames
+// with a Luby transform fountain code. A receiver rebuilds the payload from
+// any large enough subset of the frames, in any order.
+//
+// The first frames carry the blocks of the payload in order. Later frames
+// combine random sets of blocks.
+type FountainEncoder struct {
+	opts      FountainOptions
+	payload   []byte
+	blockSize int
+	numBlocks int
+	checksum  uint32
+}
+
+// NewFountainEncoder returns an encoder of payload into frames sized for
+// opts.Version and opts.Level.
+func NewFountainEncoder(payload []byte, opts FountainOptions) (*FountainEncoder, error) {
+	if opts.Version == 0 {
+		opts.Version = DefaultFountainVersion
+	}
+	if opts.ModuleSize == 0 {
+		opts.ModuleSize = DefaultFountainModuleSize
+	}
+	if opts.Delay == 0 {
+		opts.Delay = DefaultFountainFrameDelay
+	}
+
+	if len(payload) == 0 {
+		return nil, errors.New("fountain payload is empty")
+	}
+	if len(payload) > MaxFountainPayload {
+		return nil, fmt.Errorf("fountain payload of %d bytes is too large (maximum %d)", len(payload), MaxFountainPayload)
+	}
+
+	capacity, err := alphanumericCapacity(opts.Version, opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	blockSize := min(fountainEncoding.DecodedLen(capacity)-fountainHeaderLength, math.MaxUint16)
+	if blockSize < 1 {
+		return nil, fmt.Errorf("QR Code version %d is too small for fountain frames", opts.Version)
+	}
+	numBlocks := (len(payload) + blockSize - 1) / blockSize
+	if numBlocks > MaxFountainBlocks {
+		return nil, fmt.Errorf("fountain payload needs %d blocks (maximum %d); use a larger QR Code version", numBlocks, MaxFountainBlocks)
+	}
+
+	if opts.Frames == 0 {
+		opts.Frames = 2 * numBlocks
+	}
+
+	return &FountainEncoder{
+		opts:      opts,
+		payload:   payload,
+		blockSize: blockSize,
+		numBlocks: numBlocks,
+		checksum:  crc32.ChecksumIEEE(payload),
+	}, nil
+}
+
+// alphanumericCapacity returns the number of alphanumeric characters that
+// fit a QR Code of the given version and level.
+func alphanumericCapacity(version int, level RecoveryLevel) (int, error) {
+	fits := func(n int) bool {
+		_, err := NewWithForcedVersion(strings.Repeat("A", n), version, level)
+		return err == nil
+	}
+	if !fits(1) {
+		return 0, fmt.Errorf("invalid fountain frame version %d", version)
+	}
+
+	lo, hi := 1, 4296
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if fits(mid) {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	return lo, nil
+}
+
+// NumBlocks returns the number of blocks the payload is split into. Receivers
+// need slightly more frames than blocks to rebuild the payload.
+func (e *FountainEncoder) NumBlocks() int {
+	return e.numBlocks
+}
+
+// Frame returns the content of frame seed.
+func (e *FountainEncoder) Frame(seed uint32) string {
+	frame := make([]byte, 0, fountainHeaderLength+e.blockSize)
+	frame = append(frame, fountainFormat)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(e.payload)))
+	frame = binary.BigEndian.AppendUint16(frame, uint16(e.blockSize))
+	frame = binary.BigEndian.AppendUint32(frame, e.checksum)
+	frame = binary.BigEndian.AppendUint32(frame, seed)
+
+	block := make([]byte, e.blockSize)
+	for _, i := range fountainBlocks(seed, e.numBlocks) {
+		start := i * e.blockSize
+		end := min(start+e.blockSize, len(e.payload))
+		for j, b := range e.payload[start:end] {
+			block[j] ^= b
+		}
+	}
+
+	return fountainEncoding.EncodeToString(append(frame, block...))
+}
+
+// QRCode returns the QR Code of frame seed.
+func (e *FountainEncoder) QRCode(seed uint32) (*QRCode, error) {
+	return NewWithForcedVersion(e.Frame(seed), e.opts.Version, e.opts.Level)
+}
+
+// Frames returns the contents of the first n frames.
+func (e *FountainEncoder) Frames(n int) []string {
+	frames := make([]string, n)
+	for i := range frames {
+		frames[i] = e.Frame(uint32(i))
+	}
+	return frames
+}
+
+// WriteGIF writes an animated GIF of opts.Frames frames, looping forever.
+func (e *FountainEncoder) WriteGIF(out io.Writer) error {
+	anim := &gif.GIF{}
+
+	for seed := range e.opts.Frames {
+		q, err := e.QRCode(uint32(seed))
+		if err != nil {
+			return err
+		}
+
+		img, ok := q.ImageWithModuleSize(e.opts.ModuleSize).(*image.Paletted)
+		if !ok {
+			return errors.New("fountain frames must be drawn with a palette")
+		}
+
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, e.opts.Delay)
+	}
+
+	return gif.EncodeAll(out, anim)
+}
+
+// WriteFountainGIF writes payload as an animated GIF of fountain coded QR
+// Codes.
+func WriteFountainGIF(out io.Writer, payload []byte, opts FountainOptions) error {
+	e, err := NewFountainEncoder(payload, opts)
+	if err != nil {
+		return err
+	}
+	return e.WriteGIF(out)
+}
+
+// fountainBlocks returns the blocks combined in frame seed. Frames below
+// numBlocks carry a single block each.
+func fountainBlocks(seed uint32, numBlocks int) []int {
+	if int(seed) < numBlocks {
+		return []int{int(seed)}
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(seed), uint64(numBlocks)))
+	degree := robustSoliton(numBlocks, rng.Float64())
+
+	return rng.Perm(numBlocks)[:degree]
+}
+
+// robustSoliton returns the degree at cumulative probability p of the robust
+// soliton distribution over k blocks.
+func robustSoliton(k int, p float64) int {
+	if k == 1 {
+		return 1
+	}
+
+	const (
+		c     = 0.1
+		delta = 0.5
+	)
+
+	K := float64(k)
+	r := c * math.Log(K/delta) * math.Sqrt(K)
+	spike := max(1, min(k, int(math.Round(K/r))))
+
+	weights := make([]float64, k+1)
+	total := 0.0
+	for d := 1; d <= k; d++ {
+		w := 1 / (float64(d) * float64(d-1))
+		if d == 1 {
+			w = 1 / K
+		}
+
+		switch {
+		case d < spike:
+			w += r / (float64(d) * K)
+		case d == spike:
+			w += max(0, r*math.Log(r/delta)/K)
+		}
+
+		weights[d] = w
+		total += w
+	}
+
+	cumulative := 0.0
+	for d := 1; d <= k; d++ {
+		cumulative += weights[d] / total
+		if p < cumulative {
+			return d
+		}
+	}
+	return k
+}
+
+// FountainDecoder rebuilds a payload from the frames of a FountainEncoder,
+// received in any order.
+type FountainDecoder struct {
+	length    int
+	blockSize int
+	checksum  uint32
+	numBlocks int
+
+	blocks  [][]byte
+	decoded int
+	pending []*fountainEquation
+	seen    map[uint32]bool
+}
+
+// fountainEquation is a received frame: the XOR of the blocks not decoded yet.
+type fountainEquation struct {
+	blocks map[int]bool
+	data   []byte
+}
+
+// NewFountainDecoder returns a decoder for a single payload.
+func NewFountainDecoder() *FountainDecoder {
+	return &FountainDecoder{seen: map[uint32]bool{}}
+}
+
+// Add adds the content of a scanned frame, and reports whether the payload
+// is complete. Duplicate frames are ignored.
+func (d *FountainDecoder) Add(frame string) (bool, error) {
+	data, err := fountainEncoding.DecodeString(frame)
+	if err != nil {
+		return d.Done(), fmt.Errorf("invalid fountain frame: %w", err)
+	}
+	if len(data) < fountainHeaderLength || data[0] != fountainFormat {
+		return d.Done(), errors.New("invalid fountain frame header")
+	}
+
+	length := int(binary.BigEndian.Uint32(data[1:]))
+	blockSize := int(binary.BigEndian.Uint16(data[5:]))
+	checksum := binary.BigEndian.Uint32(data[7:])
+	seed := binary.BigEndian.Uint32(data[11:])
+	block := data[fountainHeaderLength:]
+
+	if blockSize == 0 || len(block) != blockSize {
+		return d.Done(), errors.New("invalid fountain frame block size")
+	}
+	if length == 0 || length > MaxFountainPayload {
+		return d.Done(), fmt.Errorf("invalid fountain payload length %d (expected 1-%d)", length, MaxFountainPayload)
+	}
+	if numBlocks := (length + blockSize - 1) / blockSize; numBlocks > MaxFountainBlocks {
+		return d.Done(), fmt.Errorf("fountain payload has too many blocks: %d (maximum %d)", numBlocks, MaxFountainBlocks)
+	}
+
+	if d.blocks == nil {
+		d.length = length
+		d.blockSize = blockSize
+		d.checksum = checksum
+		d.numBlocks = (length + blockSize - 1) / blockSize
+		d.blocks = make([][]byte, d.numBlocks)
+	} else if length != d.length || blockSize != d.blockSize || checksum != d.checksum {
+		return d.Done(), errors.New("fountain frame belongs to another payload")
+	}
+
+	if d.seen[seed] || d.Done() {
+		return d.Done(), nil
+	}
+	d.seen[seed] = true
+
+	eq := &fountainEquation{blocks: map[int]bool{}, data: block}
+	for _, i := range fountainBlocks(seed, d.numBlocks) {
+		if d.blocks[i] != nil {
+			xorBytes(eq.data, d.blocks[i])
+		} else {
+			eq.blocks[i] = true
+		}
+	}
+	d.pending = append(d.pending, eq)
+	d.peel()
+
+	return d.Done(), nil
+}
+
+// peel solves equations of a single unknown block, substituting the decoded
+// blocks into the others, until no more can be solved.
+func (d *FountainDecoder) peel() {
+	for progress := true; progress; {
+		progress = false
+
+		pending := d.pending[:0]
+		var solved []int
+		for _, eq := range d.pending {
+			if len(eq.blocks) > 1 {
+				pending = append(pending, eq)
+				continue
+			}
+			for i := range eq.blocks {
+				if d.blocks[i] == nil {
+					d.blocks[i] = eq.data
+					d.decoded++
+					solved = append(solved, i)
+				}
+			}
+		}
+		d.pending = pending
+
+		for _, i := range solved {
+			for _, eq := range d.pending {
+				if eq.blocks[i] {
+					xorBytes(eq.data, d.blocks[i])
+					delete(eq.blocks, i)
+					progress = true
+				}
+			}
+		}
+	}
+}
+
+func xorBytes(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+// Done reports whether every block of the payload has been decoded.
+func (d *FountainDecoder) Done() bool {
+	return d.blocks != nil && d.decoded == d.numBlocks
+}
+
+// Progress returns the fraction of the blocks decoded so far.
+func (d *FountainDecoder) Progress() float64 {
+	if d.blocks == nil {
+		return 0
+	}
+	return float64(d.decoded) / float64(d.numBlocks)
+}
+
+// Payload returns the rebuilt payload, once Done reports true.
+func (d *FountainDecoder) Payload() ([]byte, error) {
+	if !d.Done() {
+		return nil, errors.New("fountain payload is incomplete")
+	}
+
+	payload := make([]byte, 0, d.numBlocks*d.blockSize)
+	for _, b := range d.blocks {
+		payload = append(payload, b...)
+	}
+	payload = payload[:d.length]
+
+	if crc32.ChecksumIEEE(payload) != d.checksum {
+		return nil, errors.New("fountain payload checksum mismatch")
+	}
+	return payload, nil
+}
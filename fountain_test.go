@@ -0,0 +1,142 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/gif"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func fountainPayload(n int) []byte {
+	rng := rand.New(rand.NewPCG(1, 2))
+	payload := make([]byte, n)
+	for i := range payload {
+		payload[i] = byte(rng.Uint32())
+	}
+	return payload
+}
+
+func TestFountainRoundTrip(t *testing.T) {
+	payload := fountainPayload(20000)
+	e, err := NewFountainEncoder(payload, FountainOptions{Version: 10})
+	assert.NoError(t, err)
+	assert.True(t, e.NumBlocks() > 50)
+
+	// Frames are alphanumeric and fit the chosen version.
+	q, err := e.QRCode(uint32(e.NumBlocks() + 3))
+	assert.NoError(t, err)
+	assert.Equal(t, q.VersionNumber, 10)
+
+	// Lose every third systematic frame, and receive the rest shuffled
+	// with random frames.
+	var frames []string
+	for seed := range 3 * e.NumBlocks() {
+		if seed < e.NumBlocks() && seed%3 == 0 {
+			continue
+		}
+		frames = append(frames, e.Frame(uint32(seed)))
+	}
+	rng := rand.New(rand.NewPCG(3, 4))
+	rng.Shuffle(len(frames), func(i, j int) { frames[i], frames[j] = frames[j], frames[i] })
+
+	d := NewFountainDecoder()
+	_, err = d.Payload()
+	assert.Error(t, err)
+
+	used := 0
+	for _, f := range frames {
+		done, err := d.Add(f)
+		assert.NoError(t, err)
+		used++
+		if done {
+			break
+		}
+	}
+
+	assert.True(t, d.Done())
+	assert.Equal(t, d.Progress(), 1.0)
+	assert.True(t, used < len(frames))
+
+	got, err := d.Payload()
+	assert.NoError(t, err)
+	assert.Equal(t, got, payload)
+}
+
+func TestFountainDecoderErrors(t *testing.T) {
+	a, err := NewFountainEncoder([]byte("first payload"), FountainOptions{Version: 2})
+	assert.NoError(t, err)
+	b, err := NewFountainEncoder([]byte("second payload"), FountainOptions{Version: 2})
+	assert.NoError(t, err)
+
+	d := NewFountainDecoder()
+	_, err = d.Add("not base32!")
+	assert.Error(t, err)
+
+	_, err = d.Add(a.Frame(0))
+	assert.NoError(t, err)
+	_, err = d.Add(b.Frame(0))
+	assert.Error(t, err)
+
+	_, err = NewFountainEncoder(nil, FountainOptions{})
+	assert.Error(t, err)
+	_, err = NewFountainEncoder([]byte("x"), FountainOptions{Version: 41})
+	assert.Error(t, err)
+	_, err = NewFountainEncoder(make([]byte, MaxFountainPayload+1), FountainOptions{Version: 40})
+	assert.Error(t, err)
+	_, err = NewFountainEncoder(make([]byte, MaxFountainPayload), FountainOptions{Version: 1})
+	assert.Error(t, err)
+}
+
+// hostileFrame returns a well-formed frame announcing a payload of length
+// bytes in blocks of blockSize.
+func hostileFrame(length uint32, blockSize uint16) string {
+	frame := []byte{fountainFormat}
+	frame = binary.BigEndian.AppendUint32(frame, length)
+	frame = binary.BigEndian.AppendUint16(frame, blockSize)
+	frame = binary.BigEndian.AppendUint32(frame, 0)
+	frame = binary.BigEndian.AppendUint32(frame, 0)
+	frame = append(frame, make([]byte, blockSize)...)
+	return fountainEncoding.EncodeToString(frame)
+}
+
+func TestFountainDecoderHostileHeader(t *testing.T) {
+	for _, frame := range []string{
+		hostileFrame(0xffffffff, 1),
+		hostileFrame(MaxFountainPayload+1, 0xffff),
+		hostileFrame(MaxFountainBlocks+1, 1),
+		hostileFrame(0, 1),
+	} {
+		d := NewFountainDecoder()
+		_, err := d.Add(frame)
+		assert.Error(t, err)
+		assert.True(t, d.blocks == nil)
+	}
+
+	// The largest payload allowed is accepted.
+	d := NewFountainDecoder()
+	_, err := d.Add(hostileFrame(MaxFountainBlocks, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, d.numBlocks, MaxFountainBlocks)
+}
+
+func TestRobustSoliton(t *testing.T) {
+	assert.Equal(t, robustSoliton(1, 0.9), 1)
+	for _, k := range []int{2, 10, 1000} {
+		assert.Equal(t, robustSoliton(k, 0), 1)
+		assert.True(t, robustSoliton(k, 0.999999) <= k)
+	}
+}
+
+func TestWriteFountainGIF(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteFountainGIF(&buf, fountainPayload(500), FountainOptions{Version: 5, Frames: 6}))
+
+	anim, err := gif.DecodeAll(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, len(anim.Image), 6)
+	assert.Equal(t, anim.Delay[0], DefaultFountainFrameDelay)
+	assert.Equal(t, anim.Image[0].Bounds().Dx(), (37+8)*DefaultFountainModuleSize)
+}
@@ -0,0 +1,441 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// FrameTemplate is the decoration drawn around a framed QRCode.
+type FrameTemplate int
+
+const (
+	// FramePlain draws only the caption.
+	FramePlain FrameTemplate = iota
+	// FrameBorder draws a rounded border around the symbol and caption.
+	FrameBorder
+	// FrameScanMe draws a rounded border with a banner at the bottom,
+	// holding a label such as "SCAN ME".
+	FrameScanMe
+)
+
+const (
+	// DefaultFrameLabel is the default label of FrameScanMe.
+	DefaultFrameLabel = "SCAN ME"
+
+	// DefaultFrameTextSize is the default height of the caption and label
+	// text, in modules.
+	DefaultFrameTextSize = 2.0
+
+	// DefaultFrameBorderWidth is the default width of the border, in
+	// modules.
+	DefaultFrameBorderWidth = 1.0
+
+	// DefaultFrameCornerRadius is the default outer corner radius of the
+	// border, in modules.
+	DefaultFrameCornerRadius = 2.0
+)
+
+// FrameOptions configures a frame and caption drawn around a QRCode.
+//
+// The frame is laid out outside the quiet zone: the border clears its
+// corners, and the caption and banner are placed below it. Text too wide for
+// the symbol is scaled down to fit.
+type FrameOptions struct {
+	Template FrameTemplate
+
+	// Caption is drawn under the symbol, such as a URL or a product ID.
+	Caption string
+
+	// Label is the text of the FrameScanMe banner. Empty uses
+	// DefaultFrameLabel.
+	Label string
+
+	// Font draws the caption and label. Nil uses BitmapFont.
+	Font Font
+
+	// TextSize, BorderWidth and CornerRadius are in modules. Zero uses
+	// the defaults.
+	TextSize     float64
+	BorderWidth  float64
+	CornerRadius float64
+
+	// Color is the color of the border, banner and caption. The label is
+	// drawn in the background color. Nil uses the foreground color.
+	Color color.Color
+}
+
+// WithFrame draws a frame and caption around the QRCode. It returns an error,
+// leaving the QRCode unchanged, if a size is negative.
+//
+// The frame is drawn by the raster, SVG, PDF, EPS and TikZ output. Writers
+// of other formats return ErrFrameUnsupported, and the text and HTML output
+// draw the modules alone.
+func (q *QRCode) WithFrame(opts FrameOptions) error {
+	switch {
+	case opts.Template < FramePlain || opts.Template > FrameScanMe:
+		return fmt.Errorf("unknown frame template %d", opts.Template)
+	case opts.TextSize < 0:
+		return fmt.Errorf("frame text size %g is negative", opts.TextSize)
+	case opts.BorderWidth < 0:
+		return fmt.Errorf("frame border width %g is negative", opts.BorderWidth)
+	case opts.CornerRadius < 0:
+		return fmt.Errorf("frame corner radius %g is negative", opts.CornerRadius)
+	}
+
+	if opts.Label == "" {
+		opts.Label = DefaultFrameLabel
+	}
+	if opts.Font == nil {
+		opts.Font = BitmapFont
+	}
+	if opts.TextSize == 0 {
+		opts.TextSize = DefaultFrameTextSize
+	}
+	if opts.BorderWidth == 0 {
+		opts.BorderWidth = DefaultFrameBorderWidth
+	}
+	if opts.CornerRadius == 0 {
+		opts.CornerRadius = DefaultFrameCornerRadius
+	}
+
+	q.frame = &opts
+	return nil
+}
+
+// ErrFrameUnsupported is returned by writers of formats that cannot draw the
+// frame set by WithFrame.
+var ErrFrameUnsupported = errors.New("format cannot draw a frame")
+
+// checkUnframed returns ErrFrameUnsupported for the format if the QRCode is
+// framed.
+func (q *QRCode) checkUnframed(format string) error {
+	if q.frame != nil {
+		return fmt.Errorf("%w: %s", ErrFrameUnsupported, format)
+	}
+	return nil
+}
+
+// WithoutFrame removes the frame set by WithFrame.
+func (q *QRCode) WithoutFrame() *QRCode {
+	q.frame = nil
+	return q
+}
+
+// frameLayout is the canvas of a framed QRCode, in module coordinates with
+// the symbol and its quiet zone at the origin.
+type frameLayout struct {
+	x, y, w, h float64
+	shapes     []frameShape
+}
+
+// frameShape is an outline of the frame filled with a single color.
+type frameShape struct {
+	color   color.Color
+	evenOdd bool
+	draw    func(p pathWriter)
+}
+
+// canvas returns the frame of the QRCode around m, or the bounds of m if the
+// QRCode has no frame.
+func (q *QRCode) canvas(m *ModuleMatrix) frameLayout {
+	if q.frame == nil {
+		return frameLayout{w: float64(m.size), h: float64(m.size)}
+	}
+	return q.frame.layout(m, q.ForegroundColor, q.BackgroundColor)
+}
+
+// paintFrame paints the shapes of f with v.
+func paintFrame(v vectorPainter, f frameLayout) {
+	for _, s := range f.shapes {
+		v.paint(fill{color: s.color}, s.evenOdd, s.draw)
+	}
+}
+
+// layout places the frame around m.
+func (f *FrameOptions) layout(m *ModuleMatrix, foreground, background color.Color) frameLayout {
+	c := f.Color
+	if c == nil {
+		c = foreground
+	}
+
+	n := float64(m.size)
+	var shapes []frameShape
+
+	// fit returns the text size of s, scaled down to leave a module on
+	// either side of the symbol.
+	fit := func(s string) float64 {
+		size := f.TextSize
+		if w := f.Font.Advance(s, size); w > n-2 {
+			size *= (n - 2) / w
+		}
+		return size
+	}
+
+	// text returns the shape of s centred in the width of the symbol, with
+	// its top at y.
+	text := func(s string, y, size float64, c color.Color) frameShape {
+		x := (n - f.Font.Advance(s, size)) / 2
+		return frameShape{color: c, draw: func(p pathWriter) {
+			f.Font.Outline(pathAdapter{p}, s, x, y, size)
+		}}
+	}
+
+	bottom := n
+	if f.Caption != "" {
+		size := fit(f.Caption)
+		shapes = append(shapes, text(f.Caption, bottom, size, c))
+		bottom += size + 1
+	}
+
+	if f.Template == FramePlain {
+		return frameLayout{w: n, h: bottom, shapes: shapes}
+	}
+
+	// The inner corners are rounded, so the border keeps a gap from the
+	// quiet zone wide enough for them to clear its corners.
+	t := f.BorderWidth
+	r := max(f.CornerRadius-t, 0)
+	gap := r * (1 - math.Sqrt2/2)
+
+	if f.Template == FrameScanMe {
+		top := bottom
+		bottom += f.TextSize + 2
+
+		banner := roundedRect{-gap, top, n + 2*gap, bottom - top + gap, [4]float64{0, 0, r, r}}
+		size := fit(f.Label)
+		shapes = append(shapes,
+			frameShape{color: c, draw: banner.path},
+			text(f.Label, top+(banner.h-size)/2, size, background))
+	}
+
+	inner := roundedRect{-gap, -gap, n + 2*gap, bottom + 2*gap, [4]float64{r, r, r, r}}
+	outer := roundedRect{inner.x - t, inner.y - t, inner.w + 2*t, inner.h + 2*t, [4]float64{r + t, r + t, r + t, r + t}}
+	border := frameShape{color: c, evenOdd: true, draw: func(p pathWriter) {
+		outer.path(p)
+		inner.path(p)
+	}}
+
+	return frameLayout{
+		x:      outer.x,
+		y:      outer.y,
+		w:      outer.w,
+		h:      outer.h,
+		shapes: append([]frameShape{border}, shapes...),
+	}
+}
+
+// pathAdapter exposes a pathWriter to a Font.
+type pathAdapter struct {
+	p pathWriter
+}
+
+func (a pathAdapter) MoveTo(x, y float64) { a.p.moveTo(x, y) }
+func (a pathAdapter) LineTo(x, y float64) { a.p.lineTo(x, y) }
+func (a pathAdapter) ClosePath()          { a.p.closePath() }
+
+func (a pathAdapter) CurveTo(x1, y1, x2, y2, x, y float64) {
+	a.p.curveTo(x1, y1, x2, y2, x, y)
+}
+
+// framedImage draws the framed QRCode into an image size pixels wide, or
+// with -size pixels per module if size is negative. The height follows the
+// frame.
+func (q *QRCode) framedImage(size int) image.Image {
+	m := q.Matrix(0)
+	f := q.frame.layout(m, q.ForegroundColor, q.BackgroundColor)
+
+	var scale float64
+	if size < 0 {
+		scale = float64(-size)
+	} else {
+		scale = float64(max(size, int(math.Ceil(f.w)))) / f.w
+	}
+
+	img := q.drawFramed(m, f, framedRaster{
+		width:   int(math.Round(f.w * scale)),
+		height:  int(math.Round(f.h * scale)),
+		originX: -f.x * scale,
+		originY: -f.y * scale,
+		scale:   scale,
+	}, toNRGBA(q.BackgroundColor))
+
+	if q.RasterMode == RasterGray {
+		return toGray(img)
+	}
+	return img
+}
+
+// framedImageFit draws the framed QRCode centred in a size x size image, with
+// the largest whole number of pixels per module that fits, and returns the
+// number of pixels per module. The image is never smaller than one pixel per
+// module.
+func (q *QRCode) framedImageFit(size int) (*image.NRGBA, int) {
+	m := q.Matrix(0)
+	f := q.frame.layout(m, q.ForegroundColor, q.BackgroundColor)
+
+	extent := math.Max(f.w, f.h)
+	size = max(size, int(math.Ceil(extent)))
+	moduleSize := max(int(float64(size)/extent), 1)
+	scale := float64(moduleSize)
+
+	// The symbol starts on a whole pixel so its modules stay sharp.
+	img := q.drawFramed(m, f, framedRaster{
+		width:   size,
+		height:  size,
+		originX: math.Round((float64(size)-f.w*scale)/2 - f.x*scale),
+		originY: math.Round((float64(size)-f.h*scale)/2 - f.y*scale),
+		scale:   scale,
+	}, toNRGBA(q.BackgroundColor))
+
+	return img, moduleSize
+}
+
+// framedRaster places a framed QRCode in a width x height image. Pixel x, y
+// lies at (x-originX)/scale, (y-originY)/scale in modules.
+type framedRaster struct {
+	width, height    int
+	originX, originY float64
+	scale            float64
+}
+
+// drawFramed draws the QRCode and the shapes of its frame f over background.
+func (q *QRCode) drawFramed(m *ModuleMatrix, f frameLayout, r framedRaster, background color.NRGBA) *image.NRGBA {
+	shapes := make([]rasterShape, len(f.shapes))
+	for i, s := range f.shapes {
+		shapes[i] = newRasterShape(s)
+	}
+
+	paint := q.painter(m, background)
+	n := float64(m.size)
+
+	img := image.NewNRGBA(image.Rect(0, 0, r.width, r.height))
+	for y := range r.height {
+		for x := range r.width {
+			var sum colorSum
+			for sy := range rasterSamples {
+				fy := (float64(y) + (float64(sy)+0.5)/rasterSamples - r.originY) / r.scale
+				for sx := range rasterSamples {
+					fx := (float64(x) + (float64(sx)+0.5)/rasterSamples - r.originX) / r.scale
+
+					if fx >= 0 && fy >= 0 && fx < n && fy < n {
+						sum.add(paint(fx, fy))
+						continue
+					}
+
+					c := background
+					for _, s := range shapes {
+						if s.contains(fx, fy) {
+							c = over(s.color, c)
+						}
+					}
+					sum.add(c)
+				}
+			}
+
+			img.SetNRGBA(x, y, sum.average())
+		}
+	}
+
+	return img
+}
+
+// curveSegments is the number of line segments a curve is flattened into
+// when rasterizing frames.
+const curveSegments = 16
+
+// rasterShape is a frame shape flattened into polygons.
+type rasterShape struct {
+	color    color.NRGBA
+	evenOdd  bool
+	polygons []polygon
+	bounds   []bounds
+	current  polygon
+}
+
+type bounds struct {
+	x0, y0, x1, y1 float64
+}
+
+func newRasterShape(s frameShape) rasterShape {
+	r := &rasterShape{color: toNRGBA(s.color), evenOdd: s.evenOdd}
+	s.draw(r)
+	r.closePath()
+	return *r
+}
+
+func (r *rasterShape) moveTo(x, y float64) {
+	r.closePath()
+	r.current = polygon{{x, y}}
+}
+
+func (r *rasterShape) lineTo(x, y float64) {
+	r.current = append(r.current, point{x, y})
+}
+
+func (r *rasterShape) curveTo(x1, y1, x2, y2, x, y float64) {
+	p0 := r.current[len(r.current)-1]
+	for i := 1; i <= curveSegments; i++ {
+		t := float64(i) / curveSegments
+		u := 1 - t
+		a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+		r.lineTo(a*p0.x+b*x1+c*x2+d*x, a*p0.y+b*y1+c*y2+d*y)
+	}
+}
+
+func (r *rasterShape) closePath() {
+	if len(r.current) < 3 {
+		r.current = nil
+		return
+	}
+
+	b := bounds{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, p := range r.current {
+		b = bounds{min(b.x0, p.x), min(b.y0, p.y), max(b.x1, p.x), max(b.y1, p.y)}
+	}
+
+	r.polygons = append(r.polygons, r.current)
+	r.bounds = append(r.bounds, b)
+	r.current = nil
+}
+
+func (r *rasterShape) rect(x, y, w, h int) {
+	r.moveTo(float64(x), float64(y))
+	r.lineTo(float64(x+w), float64(y))
+	r.lineTo(float64(x+w), float64(y+h))
+	r.lineTo(float64(x), float64(y+h))
+	r.closePath()
+}
+
+// contains reports whether the point x, y is inside the shape, using its
+// fill rule.
+func (r *rasterShape) contains(x, y float64) bool {
+	winding := 0
+	for i, pg := range r.polygons {
+		b := r.bounds[i]
+		if x < b.x0 || y < b.y0 || x > b.x1 || y > b.y1 {
+			continue
+		}
+
+		for j, k := 0, len(pg)-1; j < len(pg); k, j = j, j+1 {
+			a, b := pg[k], pg[j]
+			if (a.y > y) == (b.y > y) {
+				continue
+			}
+			if x < (b.x-a.x)*(y-a.y)/(b.y-a.y)+a.x {
+				if b.y > a.y {
+					winding++
+				} else {
+					winding--
+				}
+			}
+		}
+	}
+
+	if r.evenOdd {
+		return winding%2 != 0
+	}
+	return winding != 0
+}
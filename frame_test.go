@@ -0,0 +1,222 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestWithFrameOptions(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	assert.Error(t, q.WithFrame(FrameOptions{Template: FrameTemplate(7)}))
+	assert.Error(t, q.WithFrame(FrameOptions{TextSize: -1}))
+	assert.Error(t, q.WithFrame(FrameOptions{BorderWidth: -1}))
+	assert.True(t, q.frame == nil)
+
+	assert.NoError(t, q.WithFrame(FrameOptions{Template: FrameScanMe}))
+	assert.Equal(t, q.frame.Label, DefaultFrameLabel)
+	assert.Equal(t, q.frame.Font, BitmapFont)
+	assert.Equal(t, q.frame.TextSize, DefaultFrameTextSize)
+
+	q.WithoutFrame()
+	assert.True(t, q.frame == nil)
+}
+
+func TestFrameLayoutClearsQuietZone(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	m := q.Matrix(0)
+	n := float64(m.size)
+
+	captions := []string{"", "i9si", strings.Repeat("https://i9si.com.br/", 4)}
+	for template := FramePlain; template <= FrameScanMe; template++ {
+		for _, caption := range captions {
+			assert.NoError(t, q.WithFrame(FrameOptions{Template: template, Caption: caption}))
+			f := q.frame.layout(m, q.ForegroundColor, q.BackgroundColor)
+
+			assert.True(t, f.x <= 0 && f.y <= 0)
+			assert.True(t, f.x+f.w >= n && f.y+f.h >= n)
+
+			for _, s := range f.shapes {
+				r := newRasterShape(s)
+				for _, b := range r.bounds {
+					assert.True(t, b.x0 >= f.x && b.y0 >= f.y)
+					assert.True(t, b.x1 <= f.x+f.w && b.y1 <= f.y+f.h)
+				}
+
+				// No shape covers the symbol or its quiet zone.
+				for y := 0.125; y < n; y += 0.25 {
+					for x := 0.125; x < n; x += 0.25 {
+						if r.contains(x, y) {
+							t.Fatalf("template %d with caption %q covers %g, %g", template, caption, x, y)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFramedImage(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	plain := q.Image(-4)
+
+	// Square corners keep the symbol on whole pixels.
+	assert.NoError(t, q.WithFrame(FrameOptions{
+		Template:     FrameBorder,
+		Caption:      "i9si",
+		CornerRadius: 1,
+		Color:        crimson,
+	}))
+	f := q.frame.layout(q.Matrix(0), q.ForegroundColor, q.BackgroundColor)
+
+	img := q.Image(-4).(*image.NRGBA)
+	assert.Equal(t, img.Bounds().Dx(), int(math.Round(f.w*4)))
+	assert.Equal(t, img.Bounds().Dy(), int(math.Round(f.h*4)))
+
+	// The symbol is drawn unchanged inside the frame.
+	origin := image.Pt(int(math.Round(-f.x*4)), int(math.Round(-f.y*4)))
+	for y := 0; y < plain.Bounds().Dy(); y += 3 {
+		for x := 0; x < plain.Bounds().Dx(); x += 3 {
+			assert.Equal(t, toNRGBA(img.At(origin.X+x, origin.Y+y)), toNRGBA(plain.At(x, y)))
+		}
+	}
+
+	// The border is drawn in the frame color.
+	assert.Equal(t, img.NRGBAAt(img.Bounds().Dx()/2, 1), toNRGBA(crimson))
+
+	q.WithRasterMode(RasterGray)
+	_, ok := q.Image(200).(*image.Gray)
+	assert.True(t, ok)
+	assert.Equal(t, q.Image(200).Bounds().Dx(), 200)
+}
+
+func TestFramedSVG(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	assert.NoError(t, q.WithFrame(FrameOptions{Template: FrameScanMe, Caption: "i9si"}))
+
+	svg := string(q.SVG(SVGOptions{Width: "40mm"}))
+	assert.True(t, strings.Contains(svg, `width="40mm" viewBox="-`))
+	assert.False(t, strings.Contains(svg, "height="+`"40mm"`))
+	assert.True(t, strings.Contains(svg, `fill-rule="evenodd"`))
+	assert.False(t, strings.Contains(svg, "crispEdges"))
+
+	// The label is drawn in the background color over the banner.
+	q.BackgroundColor = color.NRGBA{0xff, 0xee, 0xdd, 0xff}
+	svg = string(q.SVG(SVGOptions{}))
+	assert.Equal(t, strings.Count(svg, `fill="#ffeedd"`), 2)
+}
+
+func TestFramedRasterEntryPoints(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	assert.NoError(t, q.WithFrame(FrameOptions{
+		Template:     FrameScanMe,
+		CornerRadius: 1,
+		Color:        crimson,
+	}))
+	f := q.frame.layout(q.Matrix(0), q.ForegroundColor, q.BackgroundColor)
+
+	assert.Equal(t, q.ImageWithModuleSize(4), q.Image(-4))
+
+	// The frame fits in the square, centred, with whole modules.
+	fit := q.ImageFit(300).(*image.NRGBA)
+	assert.Equal(t, fit.Bounds(), image.Rect(0, 0, 300, 300))
+	moduleSize := int(300 / math.Max(f.w, f.h))
+	top := int(math.Round((300 - f.h*float64(moduleSize)) / 2))
+	assert.Equal(t, fit.NRGBAAt(150, top+1), toNRGBA(crimson))
+	assert.Equal(t, fit.NRGBAAt(1, 150), toNRGBA(q.BackgroundColor))
+
+	var warnings []error
+	q.OnWarning = func(err error) { warnings = append(warnings, err) }
+	img, err := q.PrintImage(PrintOptions{Size: 1, Unit: Inch, DPI: 300, MinModuleSize: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, img, image.Image(fit))
+	assert.Equal(t, len(warnings), 1)
+	printed := float64(moduleSize) / 300 * millimetresPerInch
+	assert.True(t, strings.Contains(warnings[0].Error(), fmt.Sprintf("%.3gmm", printed)))
+
+	// A quarter turn lays the frame on its side.
+	dst := image.NewNRGBA(image.Rect(0, 0, 400, 400))
+	assert.NoError(t, q.DrawInto(dst, dst.Bounds(), DrawOptions{Rotation: Rotate90, KnockOut: true}))
+	var painted image.Rectangle
+	for y := range 400 {
+		for x := range 400 {
+			if dst.NRGBAAt(x, y).A != 0 {
+				painted = painted.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	assert.True(t, painted.Dx() > painted.Dy())
+	assert.Equal(t, dst.NRGBAAt(painted.Min.X+1, 200), toNRGBA(crimson))
+
+	assert.Error(t, q.DrawInto(dst, image.Rect(0, 0, 40, 20), DrawOptions{}))
+}
+
+func TestFramedVectorOutput(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	assert.NoError(t, q.WithFrame(FrameOptions{Template: FrameScanMe, Caption: "i9si"}))
+	f := q.frame.layout(q.Matrix(0), q.ForegroundColor, q.BackgroundColor)
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WritePDF(&buf, PDFOptions{Size: 40}))
+	pdf := buf.String()
+	height := 40 * f.h / f.w * pointsPerMillimetre
+	assert.True(t, strings.Contains(pdf, fmt.Sprintf("/MediaBox [0 0 %s %s]",
+		formatNumber(40*pointsPerMillimetre), formatNumber(height))))
+	assert.True(t, strings.Contains(pdf, "f*\n"))
+
+	buf.Reset()
+	assert.NoError(t, q.WriteEPS(&buf, EPSOptions{ModuleSize: 2}))
+	eps := buf.String()
+	assert.True(t, strings.Contains(eps, fmt.Sprintf("%%%%HiResBoundingBox: 0 0 %s %s\n",
+		formatNumber(f.w*2), formatNumber(f.h*2))))
+	assert.True(t, strings.Contains(eps, "eofill\n"))
+
+	// A spot color paints the modules only.
+	buf.Reset()
+	assert.NoError(t, q.WriteEPS(&buf, EPSOptions{SpotColor: "PANTONE 186 C"}))
+	assert.True(t, strings.Contains(buf.String(), "0 0 0 1 setcmykcolor\n"))
+
+	buf.Reset()
+	assert.NoError(t, q.WriteTikZ(&buf, TikZOptions{}))
+	tikz := buf.String()
+	assert.True(t, strings.Contains(tikz, `\definecolor{qrframe0}{RGB}{0,0,0}`))
+	assert.True(t, strings.Contains(tikz, `\fill[qrframe0, even odd rule]`))
+	assert.True(t, strings.Contains(tikz, " .. controls ("))
+	assert.True(t, strings.Contains(tikz, " -- cycle"))
+}
+
+func TestFrameUnsupported(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	assert.NoError(t, q.WithFrame(FrameOptions{Caption: "i9si"}))
+
+	var buf bytes.Buffer
+	for _, err := range []error{
+		q.Render(&buf, TextRenderer{}),
+		q.WriteTerminal(&buf, TerminalOptions{Protocol: TerminalSixel}),
+		q.WritePBM(&buf, NetpbmOptions{}),
+		q.WritePGM(&buf, NetpbmOptions{}),
+		q.WriteXBM(&buf, XBMOptions{}),
+		q.WriteDXF(&buf, DXFOptions{}),
+		q.WriteGCode(&buf, GCodeOptions{}),
+	} {
+		assert.True(t, errors.Is(err, ErrFrameUnsupported))
+	}
+
+	assert.NoError(t, q.WriteTerminal(&buf, TerminalOptions{Protocol: TerminalKitty}))
+	assert.NotEmpty(t, q.ToString(false))
+}
@@ -0,0 +1,271 @@
+package qrcode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// GCodeMode is the path a laser or tool follows to engrave a QRCode.
+type GCodeMode int
+
+const (
+	// GCodeRaster scans the whole symbol in horizontal lines, alternating
+	// direction, burning the runs of engraved modules on each line.
+	GCodeRaster GCodeMode = iota
+	// GCodeContour engraves one merged region of modules at a time: its
+	// boundaries first, then horizontal lines filling it.
+	GCodeContour
+)
+
+const (
+	// DefaultGCodeModuleSize is the default size of a module, in
+	// millimetres.
+	DefaultGCodeModuleSize = 0.5
+
+	// DefaultGCodeSpotWidth is the default width of the laser spot or
+	// tool, in millimetres.
+	DefaultGCodeSpotWidth = 0.1
+
+	// DefaultGCodeFeedRate is the default engraving speed, in millimetres
+	// per minute.
+	DefaultGCodeFeedRate = 1000.0
+
+	// DefaultGCodePower is the default laser power or spindle speed, as the
+	// S word of M3.
+	DefaultGCodePower = 1000.0
+)
+
+// GCodeOptions configures the G-code output of a QRCode.
+type GCodeOptions struct {
+	Mode GCodeMode
+
+	// ModuleSize, SpotWidth and FeedRate are in millimetres and
+	// millimetres per minute. SpotWidth is the distance between lines and
+	// the inset of the paths from the edges of the modules, and must not
+	// be larger than ModuleSize. Zero uses the defaults.
+	ModuleSize float64
+	SpotWidth  float64
+	FeedRate   float64
+
+	// Power is the S word of M3. Zero uses DefaultGCodePower.
+	Power float64
+
+	// QuietZone is the width of the quiet zone in modules. Zero keeps the
+	// default quiet zone and NoQuietZone removes it.
+	QuietZone int
+
+	// Invert engraves the light modules and the quiet zone instead of the
+	// dark modules, for materials that turn dark where they are not
+	// engraved.
+	Invert bool
+}
+
+func (opts *GCodeOptions) setDefaults() error {
+	if opts.ModuleSize == 0 {
+		opts.ModuleSize = DefaultGCodeModuleSize
+	}
+	if opts.SpotWidth == 0 {
+		opts.SpotWidth = DefaultGCodeSpotWidth
+	}
+	if opts.FeedRate == 0 {
+		opts.FeedRate = DefaultGCodeFeedRate
+	}
+	if opts.Power == 0 {
+		opts.Power = DefaultGCodePower
+	}
+
+	switch {
+	case opts.Mode != GCodeRaster && opts.Mode != GCodeContour:
+		return fmt.Errorf("unknown G-code mode %d", opts.Mode)
+	case opts.ModuleSize < 0:
+		return fmt.Errorf("G-code module size %g is negative", opts.ModuleSize)
+	case opts.SpotWidth < 0 || opts.SpotWidth > opts.ModuleSize:
+		return fmt.Errorf("G-code spot width %g is out of range (expected 0-%g)", opts.SpotWidth, opts.ModuleSize)
+	case opts.FeedRate < 0:
+		return fmt.Errorf("G-code feed rate %g is negative", opts.FeedRate)
+	case opts.Power < 0:
+		return fmt.Errorf("G-code power %g is negative", opts.Power)
+	}
+	return nil
+}
+
+// WriteGCode writes a G-code program engraving the QRCode, in millimetres
+// with absolute coordinates. The origin is the bottom-left corner of the
+// quiet zone, with y pointing up. The laser or spindle is switched on with
+// M3 for every path and off with M5 between them.
+func (q *QRCode) WriteGCode(out io.Writer, opts GCodeOptions) error {
+	if err := opts.setDefaults(); err != nil {
+		return err
+	}
+	if err := q.checkUnframed("G-code"); err != nil {
+		return err
+	}
+
+	m := q.Matrix(opts.QuietZone)
+	engraved := make([][]bool, m.size)
+	for y := range engraved {
+		engraved[y] = make([]bool, m.size)
+		for x := range engraved[y] {
+			engraved[y][x] = m.dark[y][x] != opts.Invert
+		}
+	}
+
+	g := &gcodeWriter{w: bufio.NewWriter(out), opts: opts, height: float64(m.size) * opts.ModuleSize}
+	fmt.Fprintf(g.w, "; QR Code version %d, %dx%d modules of %smm\n",
+		q.VersionNumber, m.size, m.size, formatNumber(opts.ModuleSize))
+	g.w.WriteString("G21\nG90\nM5\n")
+
+	// Lines run through the centre of the spot, so the first and last are
+	// half a spot inside the edges of the modules.
+	lines := engravedLines(engraved, opts.ModuleSize, opts.SpotWidth)
+
+	switch opts.Mode {
+	case GCodeRaster:
+		for i, line := range lines {
+			g.burnLine(line, i%2 == 1)
+		}
+	case GCodeContour:
+		labels, n := regions(engraved)
+
+		outlines := make([][]contour, n)
+		for _, c := range traceContours(engraved) {
+			outlines[c.region] = append(outlines[c.region], c)
+		}
+
+		// The lines crossing every region, split at its runs.
+		fills := make([][]gcodeLine, n)
+		for _, line := range lines {
+			for _, r := range line.runs {
+				region := labels[line.row][r.x0]
+				if k := len(fills[region]); k == 0 || fills[region][k-1].y != line.y {
+					fills[region] = append(fills[region], gcodeLine{y: line.y, row: line.row})
+				}
+				k := len(fills[region]) - 1
+				fills[region][k].runs = append(fills[region][k].runs, r)
+			}
+		}
+
+		inset := opts.SpotWidth / 2 / opts.ModuleSize
+		for region := range n {
+			for _, c := range outlines[region] {
+				g.burnPath(c.inset(inset))
+			}
+			for i, line := range fills[region] {
+				g.burnLine(line, i%2 == 1)
+			}
+		}
+	}
+
+	g.w.WriteString("M5\nG0 X0 Y0\nM2\n")
+	return g.w.Flush()
+}
+
+// gcodeRun is a run of engraved modules from x0 to x1, exclusive.
+type gcodeRun struct {
+	x0, x1 int
+}
+
+// gcodeLine is a horizontal line at y millimetres from the top, through the
+// runs of engraved modules of a row.
+type gcodeLine struct {
+	y    float64
+	row  int
+	runs []gcodeRun
+}
+
+// engravedLines returns the lines spaced spotWidth apart crossing engraved
+// modules.
+func engravedLines(engraved [][]bool, moduleSize, spotWidth float64) []gcodeLine {
+	height := float64(len(engraved)) * moduleSize
+
+	var lines []gcodeLine
+	for i := 0; ; i++ {
+		y := spotWidth/2 + float64(i)*spotWidth
+		if y >= height {
+			break
+		}
+
+		row := min(int(y/moduleSize), len(engraved)-1)
+		line := gcodeLine{y: y, row: row}
+
+		for x := 0; x < len(engraved[row]); {
+			if !engraved[row][x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(engraved[row]) && engraved[row][x] {
+				x++
+			}
+			line.runs = append(line.runs, gcodeRun{start, x})
+		}
+
+		if len(line.runs) > 0 {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+type gcodeWriter struct {
+	w      *bufio.Writer
+	opts   GCodeOptions
+	height float64
+}
+
+// point returns the machine coordinates of x and y millimetres from the
+// top-left corner.
+func (g *gcodeWriter) point(x, y float64) string {
+	return fmt.Sprintf("X%s Y%s", formatNumber(x), formatNumber(g.height-y))
+}
+
+// burn moves to the first point with the laser off, then burns through the
+// others.
+func (g *gcodeWriter) burn(points []point) {
+	fmt.Fprintf(g.w, "G0 %s\n", g.point(points[0].x, points[0].y))
+	fmt.Fprintf(g.w, "M3 S%s\n", formatNumber(g.opts.Power))
+	for i, p := range points[1:] {
+		fmt.Fprintf(g.w, "G1 %s", g.point(p.x, p.y))
+		if i == 0 {
+			fmt.Fprintf(g.w, " F%s", formatNumber(g.opts.FeedRate))
+		}
+		g.w.WriteString("\n")
+	}
+	g.w.WriteString("M5\n")
+}
+
+// burnPath burns a closed path given in modules.
+func (g *gcodeWriter) burnPath(pg polygon) {
+	s := g.opts.ModuleSize
+	points := make([]point, 0, len(pg)+1)
+	for _, p := range pg {
+		points = append(points, point{p.x * s, p.y * s})
+	}
+	g.burn(append(points, points[0]))
+}
+
+// burnLine burns the runs of a line, from right to left if reverse is set.
+// Runs narrower than the spot are burnt as a dot in their centre.
+func (g *gcodeWriter) burnLine(line gcodeLine, reverse bool) {
+	s, spot := g.opts.ModuleSize, g.opts.SpotWidth
+
+	for i := range line.runs {
+		r := line.runs[i]
+		if reverse {
+			r = line.runs[len(line.runs)-1-i]
+		}
+
+		x0, x1 := float64(r.x0)*s+spot/2, float64(r.x1)*s-spot/2
+		if x1 < x0 {
+			x0 = (x0 + x1) / 2
+			x1 = x0
+		}
+		if reverse {
+			x0, x1 = x1, x0
+		}
+
+		g.burn([]point{{x0, line.y}, {x1, line.y}})
+	}
+}
@@ -0,0 +1,131 @@
+package qrcode
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+// checkGCode follows the burning moves of a G-code program and checks that
+// they cover every engraved module and no other.
+func checkGCode(t *testing.T, program string, engraved [][]bool, moduleSize float64) {
+	t.Helper()
+
+	height := float64(len(engraved)) * moduleSize
+	covered := make([][]bool, len(engraved))
+	for y := range covered {
+		covered[y] = make([]bool, len(engraved))
+	}
+
+	var x, y float64
+	on := false
+	s := bufio.NewScanner(strings.NewReader(program))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "M3":
+			on = true
+		case "M5":
+			on = false
+		case "G0", "G1":
+			nx, ny := x, y
+			for _, f := range fields[1:] {
+				v, err := strconv.ParseFloat(f[1:], 64)
+				assert.NoError(t, err)
+				switch f[0] {
+				case 'X':
+					nx = v
+				case 'Y':
+					ny = v
+				}
+			}
+
+			if fields[0] == "G1" {
+				assert.True(t, on)
+				for i := range 21 {
+					px := x + (nx-x)*float64(i)/20
+					py := height - (y + (ny-y)*float64(i)/20)
+					mx, my := int(px/moduleSize), int(py/moduleSize)
+					if !engraved[my][mx] {
+						t.Fatalf("burn at %g, %g crosses module %d, %d", px, py, mx, my)
+					}
+					covered[my][mx] = true
+				}
+			} else {
+				assert.False(t, on)
+			}
+			x, y = nx, ny
+		}
+	}
+
+	assert.False(t, on)
+	assert.Equal(t, covered, engraved)
+}
+
+func TestWriteGCode(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	m := q.Matrix(NoQuietZone)
+
+	inverted := make([][]bool, m.size)
+	for y := range inverted {
+		inverted[y] = make([]bool, m.size)
+		for x := range inverted[y] {
+			inverted[y][x] = !m.dark[y][x]
+		}
+	}
+
+	for _, mode := range []GCodeMode{GCodeRaster, GCodeContour} {
+		for _, invert := range []bool{false, true} {
+			var buf bytes.Buffer
+			assert.NoError(t, q.WriteGCode(&buf, GCodeOptions{
+				Mode:       mode,
+				ModuleSize: 0.4,
+				SpotWidth:  0.1,
+				FeedRate:   1500,
+				Power:      800,
+				QuietZone:  NoQuietZone,
+				Invert:     invert,
+			}))
+			program := buf.String()
+
+			assert.True(t, strings.Contains(program, "G21\nG90\n"))
+			assert.True(t, strings.Contains(program, "M3 S800\n"))
+			assert.True(t, strings.Contains(program, " F1500\n"))
+			assert.True(t, strings.HasSuffix(program, "M2\n"))
+
+			if invert {
+				checkGCode(t, program, inverted, 0.4)
+			} else {
+				checkGCode(t, program, m.dark, 0.4)
+			}
+		}
+	}
+}
+
+func TestGCodeOptions(t *testing.T) {
+	opts := GCodeOptions{}
+	assert.NoError(t, opts.setDefaults())
+	assert.Equal(t, opts.ModuleSize, DefaultGCodeModuleSize)
+	assert.Equal(t, opts.SpotWidth, DefaultGCodeSpotWidth)
+	assert.Equal(t, opts.FeedRate, DefaultGCodeFeedRate)
+	assert.Equal(t, opts.Power, DefaultGCodePower)
+
+	for _, opts := range []GCodeOptions{
+		{Mode: GCodeMode(5)},
+		{ModuleSize: -1},
+		{ModuleSize: 0.2, SpotWidth: 0.3},
+		{FeedRate: -1},
+		{Power: -1},
+	} {
+		assert.Error(t, opts.setDefaults())
+	}
+}
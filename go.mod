@@ -6,4 +6,7 @@ require (
 	github.com/i9si-sistemas/assert v0.0.0-20241226143514-2239efdffece
 	github.com/i9si-sistemas/bitset v0.0.0-20250425133431-3da544fa4231
 	github.com/i9si-sistemas/reedsolomon v0.0.0-20250425143232-90e93d3e9b7e
+	golang.org/x/image v0.25.0
 )
+
+require golang.org/x/text v0.23.0 // indirect
@@ -0,0 +1,247 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"io"
+	"log"
+
+	bitset "github.com/i9si-sistemas/bitset"
+)
+
+// DefaultHalftoneModuleSize is the default width of a module drawn by
+// HalftoneRenderer, in pixels.
+const DefaultHalftoneModuleSize = 9
+
+// HalftoneRenderer writes a PNG image of a QRCode blended with a picture.
+//
+// Every module is split into a 3x3 grid. The centre of data modules, which
+// readers sample, and the whole of function pattern modules keep the value of
+// the module. The other sub-modules follow a dithered version of Image, so
+// the picture shows through the code.
+//
+// Use QRCode.AdaptToImage first to bring the data modules closer to the
+// picture.
+type HalftoneRenderer struct {
+	Image image.Image
+
+	// ModuleSize is the width of a module in pixels, rounded down to a
+	// multiple of 3. Defaults to DefaultHalftoneModuleSize.
+	ModuleSize int
+
+	// ForegroundColor and BackgroundColor default to black and white.
+	ForegroundColor color.Color
+	BackgroundColor color.Color
+}
+
+func (r HalftoneRenderer) Render(w io.Writer, m *ModuleMatrix) error {
+	foreground, background := r.ForegroundColor, r.BackgroundColor
+	if foreground == nil {
+		foreground = color.Black
+	}
+	if background == nil {
+		background = color.White
+	}
+
+	moduleSize := r.ModuleSize
+	if moduleSize == 0 {
+		moduleSize = DefaultHalftoneModuleSize
+	}
+	subSize := max(moduleSize/3, 1)
+
+	dots := r.halftone(m)
+	l := rasterLayout{size: len(dots) * subSize, modules: 1, pixels: subSize}
+
+	img := plainImage(dots, l, RasterPaletted, foreground, background)
+	return encodePNG(w, img, FormatOptions{})
+}
+
+// halftone returns the dark sub-modules of m, three per module in each
+// direction. The picture is dithered with Floyd-Steinberg error diffusion,
+// which accounts for the sub-modules fixed by the symbol.
+func (r HalftoneRenderer) halftone(m *ModuleMatrix) [][]bool {
+	n := 3 * m.size
+	border := 3 * m.quietZone
+
+	values := make([][]float64, n)
+	for y := range values {
+		values[y] = make([]float64, n)
+		for x := range values[y] {
+			values[y][x] = 1
+		}
+	}
+	if r.Image != nil {
+		gray := sampleGray(r.Image, n-2*border)
+		for y, row := range gray {
+			copy(values[y+border][border:], row)
+		}
+	}
+
+	diffuse := func(x, y int, e float64) {
+		if x >= 0 && x < n && y < n {
+			values[y][x] += e
+		}
+	}
+
+	dots := make([][]bool, n)
+	for y := range dots {
+		dots[y] = make([]bool, n)
+		for x := range dots[y] {
+			mx, my := x/3, y/3
+			role := m.role[my][mx]
+
+			switch {
+			case role != RoleData:
+				dots[y][x] = m.dark[my][mx]
+				continue
+			case x%3 == 1 && y%3 == 1:
+				dots[y][x] = m.dark[my][mx]
+			default:
+				dots[y][x] = values[y][x] < 0.5
+			}
+
+			e := values[y][x]
+			if !dots[y][x] {
+				e--
+			}
+			diffuse(x+1, y, e*7/16)
+			diffuse(x-1, y+1, e*3/16)
+			diffuse(x, y+1, e*5/16)
+			diffuse(x+1, y+1, e*1/16)
+		}
+	}
+
+	return dots
+}
+
+// sampleGray returns the lightness of img, from 0 to 1, averaged over an
+// n x n grid laid over the largest centred square of img. Transparent pixels
+// are light.
+func sampleGray(img image.Image, n int) [][]float64 {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	origin := b.Min.Add(image.Pt((b.Dx()-side)/2, (b.Dy()-side)/2))
+	white := toNRGBA(color.White)
+
+	gray := make([][]float64, n)
+	for y := range gray {
+		gray[y] = make([]float64, n)
+		y0 := y * side / n
+		y1 := max((y+1)*side/n, y0+1)
+
+		for x := range gray[y] {
+			x0 := x * side / n
+			x1 := max((x+1)*side/n, x0+1)
+
+			var sum float64
+			for py := y0; py < y1; py++ {
+				for px := x0; px < x1; px++ {
+					c := over(toNRGBA(img.At(origin.X+px, origin.Y+py)), white)
+					sum += float64(color.GrayModel.Convert(c).(color.Gray).Y) / 0xff
+				}
+			}
+			gray[y][x] = sum / float64((y1-y0)*(x1-x0))
+		}
+	}
+
+	return gray
+}
+
+// AdaptToImage chooses the mask pattern and the padding codewords of the
+// QRCode so its data modules follow img as closely as possible, for use with
+// HalftoneRenderer. A nil image restores the standard encoding.
+//
+// Readers ignore the padding codewords, which follow the terminator, so they
+// may differ from the alternating pattern of the specification. The mask is
+// chosen for the likeness to img rather than the lowest penalty.
+func (q *QRCode) AdaptToImage(img image.Image) error {
+	q.targetImage = img
+	return q.reencode(q.VersionNumber, q.Level)
+}
+
+// sampleTarget sets the target of encodeForTarget from the image given to
+// AdaptToImage, sampled at the size of the current version.
+func (q *QRCode) sampleTarget() {
+	q.target = nil
+	if q.targetImage == nil {
+		return
+	}
+
+	size := q.version.symbolSize()
+	gray := sampleGray(q.targetImage, size)
+
+	q.target = make([][]bool, size)
+	for y := range q.target {
+		q.target[y] = make([]bool, size)
+		for x := range q.target[y] {
+			q.target[y][x] = gray[y][x] < 0.5
+		}
+	}
+}
+
+// encodeForTarget encodes the QRCode with the mask pattern and padding
+// codewords bringing the data modules closest to the target set by
+// AdaptToImage.
+func (q *QRCode) encodeForTarget() {
+	if len(q.target) != q.version.symbolSize() {
+		q.sampleTarget()
+	}
+
+	positions := dataModulePositions(q.version)
+	order := dataCodewordOrder(q.version)
+
+	base := bitset.Clone(q.data)
+	base.AppendNumBools(q.version.numBitsToPadToCodeword(base.Len()), false)
+
+	const numMasks int = 8
+	best := -1
+
+	for mask := range numMasks {
+		data := bitset.Clone(base)
+		for i := base.Len(); i < q.version.numDataBits(); i++ {
+			p := positions[order[i/8]*8+i%8]
+			data.AppendBools(q.target[p.Y][p.X] != maskBit(mask, p.X, p.Y))
+		}
+
+		s, err := buildRegularSymbol(q.version, mask, q.encodeBlocks(data), !q.DisableBorder)
+		if err != nil {
+			log.Panic(err.Error())
+		}
+
+		score := 0
+		for _, p := range positions {
+			if s.get(p.X, p.Y) != q.target[p.Y][p.X] {
+				score++
+			}
+		}
+
+		if best < 0 || score < best {
+			q.symbol = s
+			q.mask = mask
+			q.data = data
+			best = score
+		}
+	}
+}
+
+// dataCodewordOrder returns the position of every data codeword of version v
+// in the interleaved order written by encodeBlocks, indexed by its position
+// in the data.
+func dataCodewordOrder(v qrCodeVersion) []int {
+	blocks, codewordBlock := interleavedCodewords(v)
+
+	starts := make([]int, len(blocks))
+	numDataCodewords := 0
+	for i, b := range blocks {
+		starts[i] = numDataCodewords
+		numDataCodewords += b.numDataCodewords
+	}
+
+	order := make([]int, numDataCodewords)
+	for i, block := range codewordBlock[:numDataCodewords] {
+		order[starts[block]] = i
+		starts[block]++
+	}
+
+	return order
+}
@@ -0,0 +1,111 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+	bitset "github.com/i9si-sistemas/bitset"
+)
+
+// diagonal returns an image dark below its top-left to bottom-right diagonal.
+func diagonal(size int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	for y := range size {
+		for x := range y {
+			img.SetGray(x, y, color.Gray{})
+		}
+	}
+	return img
+}
+
+func TestAdaptToImage(t *testing.T) {
+	q, err := New(i9siDomain, Low)
+	assert.NoError(t, err)
+	plain := q.Bitmap()
+
+	fresh, err := New(i9siDomain, Low)
+	assert.NoError(t, err)
+	content := fresh.data
+
+	assert.NoError(t, q.AdaptToImage(diagonal(120)))
+	adapted := q.Bitmap()
+
+	// Read the interleaved codewords back from the symbol.
+	positions := dataModulePositions(q.version)
+	qz := q.symbol.quietZoneSize
+	read := bitset.New()
+	for _, p := range positions {
+		read.AppendBools(adapted[p.Y+qz][p.X+qz] != maskBit(q.mask, p.X, p.Y))
+	}
+
+	order := dataCodewordOrder(q.version)
+	data := bitset.New()
+	for _, i := range order {
+		data.Append(read.Substr(i*8, i*8+8))
+	}
+
+	// The content is unchanged, and the error correction matches the
+	// chosen padding.
+	assert.True(t, data.Substr(0, content.Len()).Equals(content))
+	assert.True(t, q.encodeBlocks(data).Equals(read))
+
+	matches := func(bitmap [][]bool) int {
+		n := 0
+		for _, p := range positions {
+			if bitmap[p.Y+qz][p.X+qz] == (p.X < p.Y) {
+				n++
+			}
+		}
+		return n
+	}
+	assert.True(t, matches(adapted) > matches(plain))
+
+	assert.NoError(t, q.AdaptToImage(nil))
+	assert.Equal(t, q.Bitmap(), plain)
+}
+
+func TestAdaptToImageReencode(t *testing.T) {
+	q, err := New(i9siDomain, Low)
+	assert.NoError(t, err)
+	version := q.VersionNumber
+
+	// A logo raising the version samples the image again at the new size.
+	assert.NoError(t, q.AdaptToImage(diagonal(120)))
+	assert.NoError(t, q.WithLogo(newLogo(crimson), LogoOptions{Size: 0.3}))
+	assert.True(t, q.VersionNumber > version)
+	assert.Equal(t, len(q.target), q.version.symbolSize())
+	assert.NotNil(t, q.PNG(256))
+}
+
+func TestHalftoneRenderer(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	r := HalftoneRenderer{Image: diagonal(90)}
+	m := q.Matrix(0)
+	dots := r.halftone(m)
+	assert.Equal(t, len(dots), 3*m.Size())
+
+	for y := range m.Size() {
+		for x := range m.Size() {
+			assert.Equal(t, dots[3*y+1][3*x+1], m.Dark(x, y))
+			if m.Role(x, y) != RoleData {
+				for i := range 9 {
+					assert.Equal(t, dots[3*y+i/3][3*x+i%3], m.Dark(x, y))
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.Render(&buf, r))
+	img, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dx(), 9*m.Size())
+}
@@ -0,0 +1,120 @@
+package qrcode
+
+import (
+	"fmt"
+	"html/template"
+	"image/color"
+	"strings"
+)
+
+// HTMLLayout selects the markup used by the HTML output of a QRCode.
+type HTMLLayout int
+
+const (
+	// HTMLTable draws the modules as table cells, which most email
+	// clients render.
+	HTMLTable HTMLLayout = iota
+	// HTMLGrid draws the dark modules as children of a CSS grid.
+	HTMLGrid
+)
+
+// DefaultHTMLModuleSize is the default size of a module in HTML output, in
+// pixels.
+const DefaultHTMLModuleSize = 4
+
+// HTMLOptions configures the HTML output of a QRCode.
+type HTMLOptions struct {
+	Layout HTMLLayout
+
+	// ModuleSize is the size of a module in pixels. Defaults to
+	// DefaultHTMLModuleSize.
+	ModuleSize int
+}
+
+// HTML returns a self-contained HTML fragment drawing the QRCode with inline
+// styles. The result can be used directly in an html/template.
+func (q *QRCode) HTML(opts HTMLOptions) template.HTML {
+	if opts.ModuleSize <= 0 {
+		opts.ModuleSize = DefaultHTMLModuleSize
+	}
+
+	bitmap := q.Bitmap()
+
+	switch opts.Layout {
+	case HTMLGrid:
+		return template.HTML(q.htmlGrid(bitmap, opts.ModuleSize))
+	default:
+		return template.HTML(q.htmlTable(bitmap, opts.ModuleSize))
+	}
+}
+
+// htmlTable draws one table row per row of modules, merging runs of modules
+// of the same color into a single cell with colspan.
+func (q *QRCode) htmlTable(bitmap [][]bool, moduleSize int) string {
+	size := len(bitmap) * moduleSize
+	foreground := cssColor(q.ForegroundColor)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf,
+		`<table role="img" cellpadding="0" cellspacing="0" border="0" width="%d" style="border-collapse:collapse;border-spacing:0;table-layout:fixed;width:%dpx;background:%s">`,
+		size, size, cssColor(q.BackgroundColor))
+
+	for _, row := range bitmap {
+		fmt.Fprintf(&buf, `<tr style="height:%dpx">`, moduleSize)
+		for x := 0; x < len(row); {
+			n := 1
+			for x+n < len(row) && row[x+n] == row[x] {
+				n++
+			}
+
+			colspan := ""
+			if n > 1 {
+				colspan = fmt.Sprintf(` colspan="%d"`, n)
+			}
+			background := ""
+			if row[x] {
+				background = ";background:" + foreground
+			}
+
+			fmt.Fprintf(&buf, `<td%s style="width:%dpx;height:%dpx;padding:0%s"></td>`,
+				colspan, n*moduleSize, moduleSize, background)
+			x += n
+		}
+		buf.WriteString("</tr>")
+	}
+
+	buf.WriteString("</table>")
+	return buf.String()
+}
+
+// htmlGrid draws the merged dark modules as items of a CSS grid.
+func (q *QRCode) htmlGrid(bitmap [][]bool, moduleSize int) string {
+	n := len(bitmap)
+	foreground := cssColor(q.ForegroundColor)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf,
+		`<div role="img" style="display:grid;grid-template-columns:repeat(%d,%dpx);grid-template-rows:repeat(%d,%dpx);width:%dpx;height:%dpx;background:%s">`,
+		n, moduleSize, n, moduleSize, n*moduleSize, n*moduleSize, cssColor(q.BackgroundColor))
+
+	for _, r := range mergeDarkModules(bitmap) {
+		fmt.Fprintf(&buf, `<div style="grid-area:%d/%d/span %d/span %d;background:%s"></div>`,
+			r.y+1, r.x+1, r.h, r.w, foreground)
+	}
+
+	buf.WriteString("</div>")
+	return buf.String()
+}
+
+func cssColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+
+	switch n.A {
+	case 0:
+		return "transparent"
+	case 0xff:
+		return fmt.Sprintf("#%02x%02x%02x", n.R, n.G, n.B)
+	default:
+		return fmt.Sprintf("rgba(%d,%d,%d,%.3g)", n.R, n.G, n.B, float64(n.A)/0xff)
+	}
+}
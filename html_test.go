@@ -0,0 +1,60 @@
+package qrcode
+
+import (
+	"bytes"
+	"html/template"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestHTMLTable(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	bitmap := q.Bitmap()
+	html := string(q.HTML(HTMLOptions{ModuleSize: 3}))
+
+	assert.Equal(t, strings.Count(html, "<tr"), len(bitmap))
+
+	rows := strings.Split(html, "</tr>")
+	span := regexp.MustCompile(`<td(?: colspan="(\d+)")?`)
+	for _, row := range rows[:len(rows)-1] {
+		total := 0
+		for _, m := range span.FindAllStringSubmatch(row, -1) {
+			n := 1
+			if m[1] != "" {
+				n, err = strconv.Atoi(m[1])
+				assert.NoError(t, err)
+			}
+			total += n
+		}
+		assert.Equal(t, total, len(bitmap))
+	}
+}
+
+func TestHTMLGrid(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithColors(color.RGBA{0x10, 0x20, 0x30, 0xff}, color.Transparent)
+
+	html := string(q.HTML(HTMLOptions{Layout: HTMLGrid}))
+
+	assert.True(t, strings.Contains(html, "background:transparent"))
+	assert.Equal(t, strings.Count(html, "background:#102030"), len(mergeDarkModules(q.Bitmap())))
+}
+
+func TestHTMLTemplate(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	tmpl := template.Must(template.New("email").Parse(`<p>{{.}}</p>`))
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, tmpl.Execute(buf, q.HTML(HTMLOptions{})))
+	assert.True(t, strings.HasPrefix(buf.String(), "<p><table"))
+}
@@ -0,0 +1,290 @@
+package qrcode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// DefaultLogoSize is the default width of a logo, as a fraction of the width
+// of the symbol.
+const DefaultLogoSize = 0.2
+
+// DefaultLogoErrorBudget is the default fraction of the codewords each block
+// can correct that a logo may occlude.
+const DefaultLogoErrorBudget = 0.5
+
+// LogoOptions configures a logo placed in the centre of a QRCode.
+type LogoOptions struct {
+	// Size is the width of the logo as a fraction of the width of the
+	// symbol, without its quiet zone. Zero uses DefaultLogoSize.
+	Size float64
+
+	// Padding is the number of light modules cleared around the logo.
+	Padding int
+
+	// ErrorBudget is the fraction, from 0 to 1, of the codewords each
+	// error correction block can correct that the logo may occlude. The
+	// rest is left for damage and misreads. Zero uses
+	// DefaultLogoErrorBudget: a logo always occludes some codewords, so a
+	// budget of zero could never be met.
+	ErrorBudget float64
+}
+
+// logo is an image drawn over a cleared area in the centre of the symbol.
+type logo struct {
+	image image.Image
+
+	// area is the cleared square, in symbol coordinates, including the
+	// padding.
+	area    image.Rectangle
+	padding int
+}
+
+// WithLogo places img in the centre of the QRCode, over an area of modules
+// cleared to the background color.
+//
+// The occluded codewords must stay within opts.ErrorBudget of what every
+// error correction block can correct. If they do not, the recovery level and
+// then the version are raised until they do. An error is returned, leaving
+// the QRCode unchanged, if no level and version fit the content, or if the
+// logo would cover function patterns.
+//
+// The logo is drawn by the raster and SVG output. Other vector formats leave
+// the area empty.
+func (q *QRCode) WithLogo(img image.Image, opts LogoOptions) error {
+	if opts.Size == 0 {
+		opts.Size = DefaultLogoSize
+	}
+	if opts.ErrorBudget == 0 {
+		opts.ErrorBudget = DefaultLogoErrorBudget
+	}
+
+	switch {
+	case img == nil:
+		return errors.New("logo has no image")
+	case opts.Size < 0 || opts.Size > 1:
+		return fmt.Errorf("logo size %g is out of range (expected 0-1 inclusive)", opts.Size)
+	case opts.Padding < 0:
+		return fmt.Errorf("logo padding %d is negative", opts.Padding)
+	case opts.ErrorBudget < 0 || opts.ErrorBudget > 1:
+		return fmt.Errorf("logo error budget %g is out of range (expected 0-1 inclusive)", opts.ErrorBudget)
+	}
+
+	var firstErr error
+	for version := q.VersionNumber; version <= 40; version++ {
+		for level := q.Level; level <= Highest; level++ {
+			v := getQRCodeVersion(level, version)
+			if v == nil {
+				continue
+			}
+
+			area := logoArea(v.symbolSize(), opts)
+			err := checkLogoArea(*v, area, opts.ErrorBudget)
+			if err == nil && (version != q.VersionNumber || level != q.Level) {
+				err = q.reencode(version, level)
+			}
+			if err == nil {
+				q.logo = &logo{image: img, area: area, padding: opts.Padding}
+				q.symbol = nil
+				return nil
+			}
+
+			if firstErr == nil {
+				firstErr = err
+			}
+			if errors.Is(err, errLogoCoversFunctionPatterns) {
+				break
+			}
+		}
+	}
+
+	return fmt.Errorf("cannot place logo: %w", firstErr)
+}
+
+// reencode encodes the content of the QRCode again with the given version and
+// recovery level, sampling the image set by AdaptToImage again at the new
+// size.
+func (q *QRCode) reencode(version int, level RecoveryLevel) error {
+	r, err := NewWithForcedVersion(q.Content, version, level)
+	if err != nil {
+		return err
+	}
+
+	q.Level = r.Level
+	q.VersionNumber = r.VersionNumber
+	q.encoder = r.encoder
+	q.data = r.data
+	q.version = r.version
+	q.symbol = nil
+	q.sampleTarget()
+
+	return nil
+}
+
+// logoArea returns the area cleared for a logo in a symbol of symbolSize
+// modules. Its width has the parity of the symbol so it is exactly centred.
+func logoArea(symbolSize int, opts LogoOptions) image.Rectangle {
+	w := int(math.Round(opts.Size * float64(symbolSize)))
+	if w%2 != symbolSize%2 {
+		w++
+	}
+	w += 2 * opts.Padding
+
+	start := (symbolSize - w) / 2
+	return image.Rect(start, start, start+w, start+w)
+}
+
+var errLogoCoversFunctionPatterns = errors.New("logo covers function patterns")
+
+// checkLogoArea returns an error if clearing area in a symbol of version v
+// covers function patterns, or occludes more than budget of the codewords
+// any block can correct.
+func checkLogoArea(v qrCodeVersion, area image.Rectangle, budget float64) error {
+	size := v.symbolSize()
+	if !area.In(image.Rect(0, 0, size, size)) {
+		return errLogoCoversFunctionPatterns
+	}
+
+	m := &regularSymbol{
+		version: v,
+		symbol:  newSymbol(size, 0),
+		size:    size,
+	}
+	m.addFunctionPatterns()
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			if !m.symbol.empty(x, y) {
+				return errLogoCoversFunctionPatterns
+			}
+		}
+	}
+
+	blocks, codewordBlock := interleavedCodewords(v)
+	occluded := make([]int, len(blocks))
+	seen := make([]bool, len(codewordBlock))
+
+	numBits := len(codewordBlock)*8 + v.numRemainderBits
+	m.forEachDataModule(numBits, func(i, x, y int) {
+		m.symbol.set(x, y, white)
+
+		c := i / 8
+		if c < len(codewordBlock) && !seen[c] && image.Pt(x, y).In(area) {
+			seen[c] = true
+			occluded[codewordBlock[c]]++
+		}
+	})
+
+	for i, b := range blocks {
+		correctable := (b.numCodewords - b.numDataCodewords - misdecodeProtection(v)) / 2
+		allowed := int(float64(correctable) * budget)
+		if occluded[i] > allowed {
+			return fmt.Errorf("logo occludes %d codewords of block %d (maximum is %d of %d correctable)",
+				occluded[i], i, allowed, correctable)
+		}
+	}
+
+	return nil
+}
+
+// misdecodeProtection returns the number of error correction codewords that
+// small symbols reserve against misdecodes, as listed in ISO/IEC 18004 table
+// 9. They detect errors but are not used to correct them.
+func misdecodeProtection(v qrCodeVersion) int {
+	switch {
+	case v.version == 1 && v.level == Low:
+		return 3
+	case v.version == 1 && v.level == Medium, v.version == 2 && v.level == Low:
+		return 2
+	case v.version == 1 && v.level == High, v.version == 3 && v.level == Low:
+		return 1
+	}
+	return 0
+}
+
+// interleavedCodewords returns the error correction blocks of v, and the
+// block of every codeword in the order they are placed in the symbol by
+// encodeBlocks: data codewords interleaved across blocks, then error
+// correction codewords.
+func interleavedCodewords(v qrCodeVersion) ([]block, []int) {
+	var blocks []block
+	for _, b := range v.block {
+		for range b.numBlocks {
+			blocks = append(blocks, b)
+		}
+	}
+
+	var codewordBlock []int
+	for ec := range 2 {
+		for i := 0; ; i++ {
+			added := false
+			for j, b := range blocks {
+				n := b.numDataCodewords
+				if ec == 1 {
+					n = b.numCodewords - b.numDataCodewords
+				}
+				if i < n {
+					codewordBlock = append(codewordBlock, j)
+					added = true
+				}
+			}
+			if !added {
+				break
+			}
+		}
+	}
+
+	return blocks, codewordBlock
+}
+
+// bounds returns the area of the logo and the rectangle the image is drawn
+// in, in the module coordinates of m. The image keeps its aspect ratio.
+func (l *logo) bounds(m *ModuleMatrix) (area image.Rectangle, x, y, w, h float64) {
+	area = l.area.Add(image.Pt(m.quietZone, m.quietZone))
+
+	inner := area.Inset(l.padding)
+	side := float64(inner.Dx())
+	b := l.image.Bounds()
+
+	scale := side / float64(max(b.Dx(), b.Dy(), 1))
+	w, h = float64(b.Dx())*scale, float64(b.Dy())*scale
+	x = float64(inner.Min.X) + (side-w)/2
+	y = float64(inner.Min.Y) + (side-h)/2
+
+	return area, x, y, w, h
+}
+
+// painter returns a function giving the color of the logo over background at
+// a point in module coordinates, and whether the point lies in its area.
+func (l *logo) painter(m *ModuleMatrix, background color.NRGBA) func(x, y float64) (color.NRGBA, bool) {
+	area, lx, ly, lw, lh := l.bounds(m)
+	b := l.image.Bounds()
+
+	return func(x, y float64) (color.NRGBA, bool) {
+		if x < float64(area.Min.X) || y < float64(area.Min.Y) ||
+			x >= float64(area.Max.X) || y >= float64(area.Max.Y) {
+			return color.NRGBA{}, false
+		}
+		if x < lx || y < ly || x >= lx+lw || y >= ly+lh {
+			return background, true
+		}
+
+		px := b.Min.X + int((x-lx)/lw*float64(b.Dx()))
+		py := b.Min.Y + int((y-ly)/lh*float64(b.Dy()))
+		return over(toNRGBA(l.image.At(px, py)), background), true
+	}
+}
+
+// clear marks the modules under the logo in m as light.
+func (l *logo) clear(m *ModuleMatrix) {
+	area := l.area.Add(image.Pt(m.quietZone, m.quietZone))
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			m.dark[y][x] = false
+			m.role[y][x] = RoleLogo
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func newLogo(c color.Color) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestInterleavedCodewords(t *testing.T) {
+	// Version 5 at level High has two blocks of 15 data codewords and two
+	// of 16, each with 18 error correction codewords.
+	v := getQRCodeVersion(High, 5)
+	blocks, codewordBlock := interleavedCodewords(*v)
+
+	assert.Equal(t, len(blocks), 4)
+	assert.Equal(t, len(codewordBlock), 134)
+	assert.Equal(t, codewordBlock[:5], []int{0, 1, 2, 3, 0})
+	assert.Equal(t, codewordBlock[60:64], []int{2, 3, 0, 1})
+	assert.Equal(t, codewordBlock[62:66], []int{0, 1, 2, 3})
+}
+
+func TestCheckLogoArea(t *testing.T) {
+	v := getQRCodeVersion(Highest, 5)
+	size := v.symbolSize()
+
+	assert.NoError(t, checkLogoArea(*v, logoArea(size, LogoOptions{Size: 0.2}), 0.5))
+	assert.Error(t, checkLogoArea(*v, logoArea(size, LogoOptions{Size: 0.5}), 0.5))
+
+	// The centre of version 7 is an alignment pattern.
+	v = getQRCodeVersion(Highest, 7)
+	err := checkLogoArea(*v, logoArea(v.symbolSize(), LogoOptions{Size: 0.2}), 1)
+	assert.True(t, err == errLogoCoversFunctionPatterns)
+
+	// Large enough to reach the finder patterns.
+	err = checkLogoArea(*v, logoArea(v.symbolSize(), LogoOptions{Size: 0.8}), 1)
+	assert.True(t, err == errLogoCoversFunctionPatterns)
+}
+
+func TestCheckLogoAreaMisdecodeProtection(t *testing.T) {
+	// Version 1 at level Low has 7 error correction codewords, 3 of which
+	// only detect misdecodes, so 2 can be corrected.
+	v := getQRCodeVersion(Low, 1)
+	assert.Equal(t, misdecodeProtection(*v), 3)
+	assert.Equal(t, misdecodeProtection(*getQRCodeVersion(Low, 4)), 0)
+
+	err := checkLogoArea(*v, image.Rect(8, 9, 12, 13), 1)
+	assert.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "occludes 3 codewords of block 0 (maximum is 2 of 2 correctable)"))
+}
+
+func TestLogoArea(t *testing.T) {
+	assert.Equal(t, logoArea(25, LogoOptions{Size: 0.2}), image.Rect(10, 10, 15, 15))
+	assert.Equal(t, logoArea(25, LogoOptions{Size: 0.2, Padding: 1}), image.Rect(9, 9, 16, 16))
+	assert.Equal(t, logoArea(29, LogoOptions{Size: 0.2}), image.Rect(11, 11, 18, 18))
+}
+
+func TestWithLogo(t *testing.T) {
+	q, err := New(i9siDomain, Low)
+	assert.NoError(t, err)
+	version := q.VersionNumber
+
+	assert.NoError(t, q.WithLogo(newLogo(crimson), LogoOptions{Padding: 1}))
+	assert.True(t, q.Level > Low || q.VersionNumber > version)
+
+	m := q.Matrix(0)
+	area := q.logo.area.Add(image.Pt(m.quietZone, m.quietZone))
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			assert.False(t, m.dark[y][x])
+		}
+	}
+
+	const moduleSize = 10
+	img, ok := q.Image(-moduleSize).(*image.NRGBA)
+	assert.True(t, ok)
+
+	centre := m.size * moduleSize / 2
+	assert.Equal(t, img.NRGBAAt(centre, centre), toNRGBA(crimson))
+	// The logo is twice as wide as it is high, leaving the background
+	// above it.
+	inner := area.Inset(1)
+	assert.Equal(t, img.NRGBAAt(centre, inner.Min.Y*moduleSize+2), toNRGBA(q.BackgroundColor))
+	assert.Equal(t, img.NRGBAAt(area.Min.X*moduleSize+2, centre), toNRGBA(q.BackgroundColor))
+
+	svg := string(q.SVG(SVGOptions{}))
+	assert.True(t, strings.Contains(svg, `<image `))
+	assert.True(t, strings.Contains(svg, `href="data:image/png;base64,`))
+}
+
+func TestWithLogoErrors(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	assert.Error(t, q.WithLogo(nil, LogoOptions{}))
+	assert.Error(t, q.WithLogo(newLogo(crimson), LogoOptions{Size: 2}))
+	assert.Error(t, q.WithLogo(newLogo(crimson), LogoOptions{ErrorBudget: -1}))
+
+	version, level := q.VersionNumber, q.Level
+	assert.Error(t, q.WithLogo(newLogo(crimson), LogoOptions{Size: 0.9}))
+	assert.Equal(t, q.VersionNumber, version)
+	assert.Equal(t, q.Level, level)
+	assert.True(t, q.logo == nil)
+}
@@ -0,0 +1,101 @@
+package qrcode
+
+// ModuleRole describes which part of the symbol a module belongs to.
+type ModuleRole uint8
+
+const (
+	RoleQuietZone ModuleRole = iota
+	// RoleData marks the modules holding data and error correction
+	// codewords, and the remainder bits.
+	RoleData
+	// RoleFinder marks the finder patterns and their separators.
+	RoleFinder
+	RoleAlignment
+	RoleTiming
+	// RoleFormat marks the format information, including the dark module.
+	RoleFormat
+	RoleVersion
+	// RoleLogo marks the modules cleared for a logo.
+	RoleLogo
+)
+
+// isFunctionPattern reports whether modules of the role must keep their
+// square shape for the symbol to be detected.
+func (r ModuleRole) isFunctionPattern() bool {
+	return r == RoleFinder || r == RoleAlignment || r == RoleTiming
+}
+
+// ModuleMatrix is the rendered state of every module of a QRCode, including
+// its quiet zone. It is read-only.
+type ModuleMatrix struct {
+	size      int
+	quietZone int
+	dark      [][]bool
+	role      [][]ModuleRole
+}
+
+// Matrix returns the modules of the QRCode with a quiet zone of quietZone
+// modules. Zero keeps the default quiet zone and NoQuietZone removes it.
+func (q *QRCode) Matrix(quietZone int) *ModuleMatrix {
+	dark := q.bitmapWithQuietZone(quietZone)
+	border := (len(dark) - q.symbol.symbolSize) / 2
+
+	role := make([][]ModuleRole, len(dark))
+	for y := range role {
+		role[y] = make([]ModuleRole, len(dark))
+	}
+
+	for y := range q.symbol.symbolSize {
+		for x := range q.symbol.symbolSize {
+			role[y+border][x+border] = q.symbol.roleAt(x, y)
+		}
+	}
+
+	m := &ModuleMatrix{
+		size:      len(dark),
+		quietZone: border,
+		dark:      dark,
+		role:      role,
+	}
+
+	if q.logo != nil {
+		q.logo.clear(m)
+	}
+
+	return m
+}
+
+// get returns whether the module at x, y is dark. Modules outside of the
+// matrix are light.
+func (m *ModuleMatrix) get(x, y int) bool {
+	if x < 0 || y < 0 || x >= m.size || y >= m.size {
+		return false
+	}
+	return m.dark[y][x]
+}
+
+// Size returns the width and height of the matrix in modules, including the
+// quiet zone.
+func (m *ModuleMatrix) Size() int {
+	return m.size
+}
+
+// QuietZone returns the width of the quiet zone in modules.
+func (m *ModuleMatrix) QuietZone() int {
+	return m.quietZone
+}
+
+// Dark reports whether the module at x, y is dark. Modules outside of the
+// matrix are light.
+func (m *ModuleMatrix) Dark(x, y int) bool {
+	return m.get(x, y)
+}
+
+// Role returns the part of the symbol the module at x, y belongs to. Modules
+// outside of the matrix belong to the quiet zone.
+func (m *ModuleMatrix) Role(x, y int) ModuleRole {
+	if x < 0 || y < 0 || x >= m.size || y >= m.size {
+		return RoleQuietZone
+	}
+	return m.role[y][x]
+}
@@ -0,0 +1,286 @@
+package qrcode
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultPNGGenerator is the default generator recorded in PNG metadata.
+const DefaultPNGGenerator = "github.com/i9si-sistemas/qrcode"
+
+// PNG text keywords of the metadata. Content that is not UTF-8 text is
+// stored hex-encoded under pngKeywordContentHex.
+const (
+	pngKeywordSoftware   = "Software"
+	pngKeywordContent    = "QRCode Content"
+	pngKeywordContentHex = "QRCode Content Hex"
+	pngKeywordVersion    = "QRCode Version"
+	pngKeywordLevel      = "QRCode Level"
+	pngKeywordMask       = "QRCode Mask"
+)
+
+// maxPNGTextLength is the largest chunk read by ReadPNGMetadata, and the
+// largest text it decompresses from an iTXt chunk.
+const maxPNGTextLength = 1 << 24
+
+// levelLetters are the names of the recovery levels in the specification.
+var levelLetters = [...]string{Low: "L", Medium: "M", High: "Q", Highest: "H"}
+
+// ErrNoPNGMetadata is returned by ReadPNGMetadata for PNG files without the
+// metadata of a QRCode.
+var ErrNoPNGMetadata = errors.New("PNG has no QR Code metadata")
+
+// PNGMetadata describes the QRCode drawn in a PNG file. The content is
+// stored in an iTXt chunk, so it keeps any Unicode text, or hex-encoded in a
+// tEXt chunk if it is not valid UTF-8 or contains NUL bytes. The other
+// fields are stored in tEXt chunks, or in an iTXt chunk for a generator that
+// is not Latin-1 text.
+type PNGMetadata struct {
+	Content   string
+	Version   int
+	Level     RecoveryLevel
+	Mask      int
+	Generator string
+}
+
+// WithPNGMetadata records the content and encoding of the QRCode in the PNG
+// images it writes, for ReadPNGMetadata. An empty generator uses
+// DefaultPNGGenerator. Invalid UTF-8 and NUL characters in the generator are
+// replaced by U+FFFD.
+func (q *QRCode) WithPNGMetadata(generator string) *QRCode {
+	if generator == "" {
+		generator = DefaultPNGGenerator
+	}
+	generator = strings.ToValidUTF8(generator, "\uFFFD")
+	q.pngGenerator = strings.ReplaceAll(generator, "\x00", "\uFFFD")
+	return q
+}
+
+// WithoutPNGMetadata stops recording metadata in PNG images.
+func (q *QRCode) WithoutPNGMetadata() *QRCode {
+	q.pngGenerator = ""
+	return q
+}
+
+// pngMetadata returns the text chunks recording the QRCode.
+func (q *QRCode) pngMetadata() []byte {
+	q.encode()
+
+	buf := new(bytes.Buffer)
+	writeTextChunk := func(keyword string, text []byte) {
+		writePNGChunk(buf, "tEXt", append([]byte(keyword+"\x00"), text...))
+	}
+	// An iTXt chunk holds the keyword, the compression flag and method,
+	// and empty language and translated keyword fields.
+	writeITXtChunk := func(keyword, text string) {
+		writePNGChunk(buf, "iTXt", []byte(keyword+"\x00\x00\x00\x00\x00"+text))
+	}
+
+	if utf8.ValidString(q.Content) && !strings.Contains(q.Content, "\x00") {
+		writeITXtChunk(pngKeywordContent, q.Content)
+	} else {
+		writeTextChunk(pngKeywordContentHex, []byte(hex.EncodeToString([]byte(q.Content))))
+	}
+	writeTextChunk(pngKeywordVersion, []byte(strconv.Itoa(q.VersionNumber)))
+	writeTextChunk(pngKeywordLevel, []byte(levelLetters[q.Level]))
+	writeTextChunk(pngKeywordMask, []byte(strconv.Itoa(q.mask)))
+	if generator, ok := toLatin1(q.pngGenerator); ok {
+		writeTextChunk(pngKeywordSoftware, generator)
+	} else {
+		writeITXtChunk(pngKeywordSoftware, q.pngGenerator)
+	}
+
+	return buf.Bytes()
+}
+
+// pngWriter returns out, or a writer inserting the metadata of the QRCode
+// into the PNG written to out if WithPNGMetadata is set.
+func (q *QRCode) pngWriter(out io.Writer) io.Writer {
+	if q.pngGenerator == "" {
+		return out
+	}
+	return &pngChunkInserter{w: out, chunks: q.pngMetadata()}
+}
+
+// pngChunkInserter passes a PNG through, writing chunks after its IHDR
+// chunk.
+type pngChunkInserter struct {
+	w       io.Writer
+	chunks  []byte
+	written int
+}
+
+func (c *pngChunkInserter) Write(p []byte) (int, error) {
+	if c.written >= pngHeaderLength {
+		return c.w.Write(p)
+	}
+
+	n := min(len(p), pngHeaderLength-c.written)
+	if _, err := c.w.Write(p[:n]); err != nil {
+		return 0, err
+	}
+	c.written += n
+
+	if c.written == pngHeaderLength {
+		if _, err := c.w.Write(c.chunks); err != nil {
+			return n, err
+		}
+	}
+
+	if n < len(p) {
+		m, err := c.w.Write(p[n:])
+		return n + m, err
+	}
+	return n, nil
+}
+
+// ReadPNGMetadata returns the QRCode metadata recorded in a PNG file by
+// WithPNGMetadata, reading the chunks before the image data. It returns
+// ErrNoPNGMetadata if the file has none.
+func ReadPNGMetadata(r io.Reader) (*PNGMetadata, error) {
+	br := bufio.NewReader(r)
+
+	signature := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(br, signature); err != nil || string(signature) != pngSignature {
+		return nil, errors.New("not a PNG file")
+	}
+
+	text := map[string]string{}
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			return nil, fmt.Errorf("reading PNG chunk: %w", err)
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		name := string(header[4:])
+
+		if name == "IDAT" || name == "IEND" {
+			break
+		}
+		if length > maxPNGTextLength {
+			return nil, fmt.Errorf("PNG chunk %q is too long", name)
+		}
+
+		data := make([]byte, length+4)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return nil, fmt.Errorf("reading PNG chunk %q: %w", name, err)
+		}
+		data, sum := data[:length], binary.BigEndian.Uint32(data[length:])
+		if crc32.Update(crc32.ChecksumIEEE(header[4:]), crc32.IEEETable, data) != sum {
+			return nil, fmt.Errorf("PNG chunk %q has a bad checksum", name)
+		}
+
+		switch name {
+		case "tEXt":
+			if keyword, value, ok := bytes.Cut(data, []byte{0}); ok {
+				text[string(keyword)] = fromLatin1(value)
+			}
+		case "iTXt":
+			keyword, value, err := parseITXt(data)
+			if err != nil {
+				return nil, err
+			}
+			text[keyword] = value
+		}
+	}
+
+	content, ok := text[pngKeywordContent]
+	if encoded, isHex := text[pngKeywordContentHex]; !ok && isHex {
+		decoded, err := hex.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid QR Code content: %w", err)
+		}
+		content, ok = string(decoded), true
+	}
+	if !ok {
+		return nil, ErrNoPNGMetadata
+	}
+
+	m := &PNGMetadata{Content: content, Generator: text[pngKeywordSoftware]}
+	var err error
+	if m.Version, err = strconv.Atoi(text[pngKeywordVersion]); err != nil {
+		return nil, fmt.Errorf("invalid QR Code version %q", text[pngKeywordVersion])
+	}
+	if m.Mask, err = strconv.Atoi(text[pngKeywordMask]); err != nil {
+		return nil, fmt.Errorf("invalid QR Code mask %q", text[pngKeywordMask])
+	}
+
+	level := -1
+	for l, letter := range levelLetters {
+		if letter == text[pngKeywordLevel] {
+			level = l
+		}
+	}
+	if level < 0 {
+		return nil, fmt.Errorf("invalid QR Code level %q", text[pngKeywordLevel])
+	}
+	m.Level = RecoveryLevel(level)
+
+	return m, nil
+}
+
+// toLatin1 returns s encoded as Latin-1, and false if s has characters that
+// Latin-1 cannot hold.
+func toLatin1(s string) ([]byte, bool) {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r == 0 || r > 0xff {
+			return nil, false
+		}
+		b = append(b, byte(r))
+	}
+	return b, true
+}
+
+// fromLatin1 returns the Latin-1 text b as a string.
+func fromLatin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// parseITXt returns the keyword and text of an iTXt chunk.
+func parseITXt(data []byte) (string, string, error) {
+	keyword, rest, ok := bytes.Cut(data, []byte{0})
+	if !ok || len(rest) < 2 {
+		return "", "", errors.New("invalid PNG iTXt chunk")
+	}
+	compressed := rest[0] == 1
+
+	// Skip the language tag and the translated keyword.
+	rest = rest[2:]
+	for range 2 {
+		if _, rest, ok = bytes.Cut(rest, []byte{0}); !ok {
+			return "", "", errors.New("invalid PNG iTXt chunk")
+		}
+	}
+
+	if !compressed {
+		return string(keyword), string(rest), nil
+	}
+
+	z, err := zlib.NewReader(bytes.NewReader(rest))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid PNG iTXt chunk: %w", err)
+	}
+	text, err := io.ReadAll(io.LimitReader(z, maxPNGTextLength+1))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid PNG iTXt chunk: %w", err)
+	}
+	if len(text) > maxPNGTextLength {
+		return "", "", fmt.Errorf("PNG iTXt chunk %q is too long", keyword)
+	}
+	return string(keyword), string(text), nil
+}
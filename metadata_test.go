@@ -0,0 +1,116 @@
+package qrcode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"image/png"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestPNGMetadata(t *testing.T) {
+	q, err := New("https://i9si.com.br/ação", High)
+	assert.NoError(t, err)
+	q.WithPNGMetadata("")
+
+	for _, data := range [][]byte{q.PNG(-2), q.WithFinderStyle(FinderStyle{Outer: EyeCircle}).PNG(-2)} {
+		// The image still decodes.
+		_, err = png.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+
+		m, err := ReadPNGMetadata(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, *m, PNGMetadata{
+			Content:   q.Content,
+			Version:   q.VersionNumber,
+			Level:     High,
+			Mask:      q.mask,
+			Generator: DefaultPNGGenerator,
+		})
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WithPNGMetadata("label-printer/2.1").WritePrintPNG(&buf, PrintOptions{Size: 20}))
+	m, err := ReadPNGMetadata(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, m.Generator, "label-printer/2.1")
+
+	_, err = ReadPNGMetadata(bytes.NewReader(q.WithoutPNGMetadata().PNG(-2)))
+	assert.True(t, errors.Is(err, ErrNoPNGMetadata))
+
+	_, err = ReadPNGMetadata(bytes.NewReader([]byte("GIF89a")))
+	assert.Error(t, err)
+}
+
+func TestPNGMetadataEncoding(t *testing.T) {
+	// Byte mode content need not be UTF-8.
+	q, err := New("\xff\xfe\x00binary", Medium)
+	assert.NoError(t, err)
+	data := q.WithPNGMetadata("café").PNG(-2)
+	assert.False(t, bytes.Contains(data, []byte("iTXt")))
+	assert.True(t, bytes.Contains(data, []byte("QRCode Content Hex\x00fffe0062696e617279")))
+	assert.True(t, bytes.Contains(data, []byte("Software\x00caf\xe9")))
+
+	m, err := ReadPNGMetadata(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, m.Content, q.Content)
+	assert.Equal(t, m.Generator, "café")
+
+	// A generator beyond Latin-1 is stored as UTF-8 in an iTXt chunk.
+	data = q.WithPNGMetadata("生成器\x00\xff").PNG(-2)
+	assert.True(t, bytes.Contains(data, []byte("iTXt")))
+	m, err = ReadPNGMetadata(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, m.Generator, "生成器\uFFFD\uFFFD")
+}
+
+func TestPNGChunkInserter(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	data := q.PNG(-1)
+	chunks := []byte("chunks")
+
+	// The chunks land after the IHDR chunk however the PNG is split.
+	for _, step := range []int{1, 7, 33, 40, len(data)} {
+		var buf bytes.Buffer
+		w := &pngChunkInserter{w: &buf, chunks: chunks}
+		for i := 0; i < len(data); i += step {
+			_, err := w.Write(data[i:min(i+step, len(data))])
+			assert.NoError(t, err)
+		}
+
+		out := buf.Bytes()
+		assert.Equal(t, out[:pngHeaderLength], data[:pngHeaderLength])
+		assert.Equal(t, out[pngHeaderLength:pngHeaderLength+len(chunks)], chunks)
+		assert.Equal(t, out[pngHeaderLength+len(chunks):], data[pngHeaderLength:])
+	}
+}
+
+func TestParseCompressedITXt(t *testing.T) {
+	var z bytes.Buffer
+	w := zlib.NewWriter(&z)
+	w.Write([]byte("compressed text"))
+	w.Close()
+
+	chunk := append([]byte("Comment\x00\x01\x00en\x00Kommentar\x00"), z.Bytes()...)
+	keyword, text, err := parseITXt(chunk)
+	assert.NoError(t, err)
+	assert.Equal(t, keyword, "Comment")
+	assert.Equal(t, text, "compressed text")
+
+	_, _, err = parseITXt([]byte("Comment"))
+	assert.Error(t, err)
+
+	// Text decompressing past the limit is rejected.
+	z.Reset()
+	w = zlib.NewWriter(&z)
+	w.Write(make([]byte, maxPNGTextLength+1))
+	w.Close()
+	assert.True(t, z.Len() < 1<<16)
+
+	chunk = append([]byte("Comment\x00\x01\x00\x00\x00"), z.Bytes()...)
+	_, _, err = parseITXt(chunk)
+	assert.Error(t, err)
+}
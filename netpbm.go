@@ -0,0 +1,167 @@
+package qrcode
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+)
+
+// NetpbmOptions configures the PBM and PGM output of a QRCode.
+type NetpbmOptions struct {
+	// Scale is the width of a module in pixels. Zero uses 1.
+	Scale int
+
+	// QuietZone is the width of the quiet zone in modules. Zero keeps the
+	// default quiet zone and NoQuietZone removes it.
+	QuietZone int
+
+	// Plain writes the ASCII variant of the format, P1 or P2, instead of
+	// the binary one, P4 or P5.
+	Plain bool
+}
+
+// plainLineLength is the longest line of a plain Netpbm file.
+const plainLineLength = 70
+
+// WritePBM writes a bilevel Portable BitMap of the QRCode, with dark modules
+// as 1.
+func (q *QRCode) WritePBM(out io.Writer, opts NetpbmOptions) error {
+	if err := q.checkUnframed("PBM"); err != nil {
+		return err
+	}
+
+	rows, err := q.scaledBitmap(opts.Scale, opts.QuietZone)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(out)
+	width, height := len(rows[0]), len(rows)
+
+	if opts.Plain {
+		fmt.Fprintf(w, "P1\n%d %d\n", width, height)
+		for _, row := range rows {
+			writePlainValues(w, row, func(dark bool) string {
+				if dark {
+					return "1"
+				}
+				return "0"
+			})
+		}
+		return w.Flush()
+	}
+
+	fmt.Fprintf(w, "P4\n%d %d\n", width, height)
+	packed := make([]byte, (width+7)/8)
+	for _, row := range rows {
+		clear(packed)
+		for x, dark := range row {
+			if dark {
+				packed[x/8] |= 0x80 >> (x % 8)
+			}
+		}
+		w.Write(packed)
+	}
+	return w.Flush()
+}
+
+// WritePGM writes a Portable GrayMap of the QRCode, with the modules in the
+// gray levels of the foreground and background colors. Transparent colors
+// are drawn over a white page.
+func (q *QRCode) WritePGM(out io.Writer, opts NetpbmOptions) error {
+	if err := q.checkColors(); err != nil {
+		return err
+	}
+	if err := q.checkUnframed("PGM"); err != nil {
+		return err
+	}
+
+	rows, err := q.scaledBitmap(opts.Scale, opts.QuietZone)
+	if err != nil {
+		return err
+	}
+
+	foreground, background := q.pageColors()
+	dark := color.GrayModel.Convert(foreground).(color.Gray).Y
+	light := color.GrayModel.Convert(background).(color.Gray).Y
+	level := func(d bool) byte {
+		if d {
+			return dark
+		}
+		return light
+	}
+
+	w := bufio.NewWriter(out)
+	width, height := len(rows[0]), len(rows)
+
+	if opts.Plain {
+		fmt.Fprintf(w, "P2\n%d %d\n255\n", width, height)
+		for _, row := range rows {
+			writePlainValues(w, row, func(d bool) string { return fmt.Sprint(level(d)) })
+		}
+		return w.Flush()
+	}
+
+	fmt.Fprintf(w, "P5\n%d %d\n255\n", width, height)
+	levels := make([]byte, width)
+	for _, row := range rows {
+		for x, d := range row {
+			levels[x] = level(d)
+		}
+		w.Write(levels)
+	}
+	return w.Flush()
+}
+
+// writePlainValues writes a row of a plain Netpbm file, separating the
+// values with spaces and breaking lines before plainLineLength.
+func writePlainValues(w *bufio.Writer, row []bool, value func(dark bool) string) {
+	n := 0
+	for i, dark := range row {
+		v := value(dark)
+		switch {
+		case i == 0:
+		case n+1+len(v) > plainLineLength:
+			w.WriteByte('\n')
+			n = 0
+		default:
+			w.WriteByte(' ')
+			n++
+		}
+		w.WriteString(v)
+		n += len(v)
+	}
+	w.WriteByte('\n')
+}
+
+// scaledBitmap returns the bitmap of the QRCode with a quiet zone of
+// quietZone modules, with every module repeated scale times in each
+// direction. Repeated rows share the same slice.
+func (q *QRCode) scaledBitmap(scale, quietZone int) ([][]bool, error) {
+	if scale < 0 {
+		return nil, fmt.Errorf("scale %d is negative", scale)
+	}
+	scale = max(scale, 1)
+
+	bitmap := q.bitmapWithQuietZone(quietZone)
+	if len(bitmap) == 0 {
+		return nil, errors.New("QRCode has no modules")
+	}
+
+	rows := make([][]bool, 0, len(bitmap)*scale)
+	for _, row := range bitmap {
+		scaled := make([]bool, len(row)*scale)
+		for x, dark := range row {
+			for i := range scale {
+				scaled[x*scale+i] = dark
+			}
+		}
+		for range scale {
+			rows = append(rows, scaled)
+		}
+	}
+
+	return rows, nil
+}
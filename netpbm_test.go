@@ -0,0 +1,117 @@
+package qrcode
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestScaledBitmap(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	bitmap := q.Bitmap()
+
+	rows, err := q.scaledBitmap(3, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, len(rows), 3*len(bitmap))
+	for y, row := range rows {
+		assert.Equal(t, len(row), 3*len(bitmap))
+		for x, dark := range row {
+			assert.Equal(t, dark, bitmap[y/3][x/3])
+		}
+	}
+
+	rows, err = q.scaledBitmap(0, NoQuietZone)
+	assert.NoError(t, err)
+	assert.Equal(t, len(rows), len(bitmap)-8)
+
+	_, err = q.scaledBitmap(-1, 0)
+	assert.Error(t, err)
+}
+
+func TestWritePBM(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	bitmap := q.Bitmap()
+	n := len(bitmap)
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WritePBM(&buf, NetpbmOptions{Scale: 2}))
+
+	header := fmt.Sprintf("P4\n%d %d\n", 2*n, 2*n)
+	data := buf.Bytes()
+	assert.True(t, bytes.HasPrefix(data, []byte(header)))
+
+	data = data[len(header):]
+	stride := (2*n + 7) / 8
+	assert.Equal(t, len(data), 2*n*stride)
+	for y := range 2 * n {
+		for x := range 2 * n {
+			bit := data[y*stride+x/8]&(0x80>>(x%8)) != 0
+			assert.Equal(t, bit, bitmap[y/2][x/2])
+		}
+	}
+
+	buf.Reset()
+	assert.NoError(t, q.WritePBM(&buf, NetpbmOptions{Plain: true}))
+
+	s := bufio.NewScanner(&buf)
+	s.Scan()
+	assert.Equal(t, s.Text(), "P1")
+	s.Scan()
+	assert.Equal(t, s.Text(), fmt.Sprintf("%d %d", n, n))
+
+	var values []string
+	for s.Scan() {
+		assert.True(t, len(s.Text()) <= plainLineLength)
+		values = append(values, strings.Fields(s.Text())...)
+	}
+	assert.Equal(t, len(values), n*n)
+	for i, v := range values {
+		assert.Equal(t, v == "1", bitmap[i/n][i%n])
+	}
+}
+
+func TestWritePGM(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithColors(navy, color.White)
+	bitmap := q.Bitmap()
+	n := len(bitmap)
+
+	dark := color.GrayModel.Convert(navy).(color.Gray).Y
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WritePGM(&buf, NetpbmOptions{QuietZone: NoQuietZone}))
+
+	header := fmt.Sprintf("P5\n%d %d\n255\n", n-8, n-8)
+	data := buf.Bytes()
+	assert.True(t, bytes.HasPrefix(data, []byte(header)))
+	data = data[len(header):]
+	assert.Equal(t, len(data), (n-8)*(n-8))
+	assert.Equal(t, data[0], dark)
+	assert.Equal(t, data[7], uint8(0xff))
+
+	buf.Reset()
+	assert.NoError(t, q.WritePGM(&buf, NetpbmOptions{Plain: true, Scale: 5}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, lines[0], "P2")
+	assert.Equal(t, lines[2], "255")
+	for _, line := range lines[3:] {
+		assert.True(t, len(line) <= plainLineLength)
+	}
+	assert.Equal(t, len(strings.Fields(strings.Join(lines[3:], " "))), 25*n*n)
+
+	// A transparent background is drawn as the white page.
+	q.WithTransparentBackground()
+	buf.Reset()
+	assert.NoError(t, q.WritePGM(&buf, NetpbmOptions{QuietZone: NoQuietZone}))
+	data = buf.Bytes()[len(header):]
+	assert.Equal(t, data[0], dark)
+	assert.Equal(t, data[7], uint8(0xff))
+}
@@ -0,0 +1,328 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"slices"
+	"strings"
+)
+
+const pointsPerMillimetre = 72 / 25.4
+
+// DefaultPDFSize is the default width of the QR Code in a PDF, in millimetres.
+const DefaultPDFSize = 30
+
+// PDFOptions configures the PDF output of a QRCode. All lengths are in
+// millimetres.
+type PDFOptions struct {
+	// Size is the width of the QR Code including its quiet zone, or of
+	// its frame. The height follows the frame. Defaults to DefaultPDFSize.
+	Size float64
+
+	// PageWidth and PageHeight set the page size. When zero the page
+	// is just large enough to hold the QR Code at its position.
+	PageWidth  float64
+	PageHeight float64
+
+	// X and Y position the QR Code relative to the top-left corner of
+	// the page.
+	X float64
+	Y float64
+}
+
+// WritePDF writes a single page vector PDF of the QRCode.
+//
+// Colors of type color.CMYK are written as DeviceCMYK, every other color as
+// DeviceRGB. Partially transparent colors are painted with their opacity,
+// and fully transparent colors are not painted.
+func (q *QRCode) WritePDF(out io.Writer, opts PDFOptions) error {
+	if opts.Size == 0 {
+		opts.Size = DefaultPDFSize
+	}
+	if opts.Size < 0 || opts.X < 0 || opts.Y < 0 {
+		return errors.New("invalid PDF size or position")
+	}
+	if err := q.checkColors(); err != nil {
+		return err
+	}
+
+	frame := q.canvas(q.Matrix(0))
+	height := opts.Size * frame.h / frame.w
+	if opts.PageWidth == 0 {
+		opts.PageWidth = opts.X + opts.Size
+	}
+	if opts.PageHeight == 0 {
+		opts.PageHeight = opts.Y + height
+	}
+
+	width := opts.Size * pointsPerMillimetre
+	height *= pointsPerMillimetre
+	pageWidth := opts.PageWidth * pointsPerMillimetre
+	pageHeight := opts.PageHeight * pointsPerMillimetre
+	x := opts.X * pointsPerMillimetre
+	y := pageHeight - opts.Y*pointsPerMillimetre - height
+
+	// The XObject is drawn one unit wide and scaled to the width.
+	page := fmt.Sprintf("q %s 0 0 %s %s %s cm /QR Do Q\n",
+		formatNumber(width), formatNumber(width), formatNumber(x), formatNumber(y))
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /XObject << /QR 5 0 R >> >> /Contents 4 0 R >>",
+			formatNumber(pageWidth), formatNumber(pageHeight)),
+		pdfStream("", page),
+		q.pdfXObject(1),
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(objects)+1, xref)
+
+	if _, err := out.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// WritePDFXObject writes the QRCode as a PDF Form XObject of the given width
+// in millimetres, for embedding into an existing PDF. The height follows the
+// frame of a framed QRCode.
+//
+// The output is the body of an indirect object: the caller wraps it in
+// "N 0 obj" and "endobj" and references it from a page's resources.
+func (q *QRCode) WritePDFXObject(out io.Writer, size float64) error {
+	if size <= 0 {
+		return errors.New("invalid PDF size")
+	}
+	if err := q.checkColors(); err != nil {
+		return err
+	}
+
+	obj := q.pdfXObject(size * pointsPerMillimetre)
+	if _, err := io.WriteString(out, obj); err != nil {
+		return err
+	}
+	return nil
+}
+
+// pdfXObject returns a Form XObject drawing the QRCode and its frame with the
+// given width in points.
+func (q *QRCode) pdfXObject(width float64) string {
+	m := q.Matrix(0)
+	frame := q.canvas(m)
+	scale := width / frame.w
+	height := frame.h * scale
+
+	content := new(bytes.Buffer)
+	fmt.Fprintf(content, "%s 0 0 %s %s %s cm\n",
+		formatNumber(scale), formatNumber(-scale),
+		formatNumber(-frame.x*scale), formatNumber(height+frame.y*scale))
+
+	painter := &pdfPainter{buf: content, alpha: 0xff}
+	if painter.setFill(q.BackgroundColor) {
+		fmt.Fprintf(content, "%s %s %s %s re f\n",
+			formatNumber(frame.x), formatNumber(frame.y), formatNumber(frame.w), formatNumber(frame.h))
+	}
+
+	q.drawVector(painter, m)
+	paintFrame(painter, frame)
+
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 %s %s] ",
+		formatNumber(width), formatNumber(height))
+	var resources string
+	if len(painter.shadings) > 0 {
+		resources += "/Shading << "
+		for i, shading := range painter.shadings {
+			resources += fmt.Sprintf("/Sh%d %s ", i, shading)
+		}
+		resources += ">> "
+	}
+	if len(painter.alphas) > 0 {
+		resources += "/ExtGState << "
+		for i, alpha := range painter.alphas {
+			resources += fmt.Sprintf("/GS%d << /ca %s >> ", i, formatNumber(float64(alpha)/0xff))
+		}
+		resources += ">> "
+	}
+	if resources != "" {
+		dict += "/Resources << " + resources + ">> "
+	}
+
+	return pdfStream(dict, content.String())
+}
+
+type pdfPainter struct {
+	buf      *bytes.Buffer
+	shadings []string
+
+	// alphas are the fill opacities of the graphics states used, and alpha
+	// the opacity in effect.
+	alphas []uint8
+	alpha  uint8
+}
+
+// setFill writes the operators filling with c, selecting a graphics state
+// with its opacity when needed. It returns false when c is fully
+// transparent.
+func (v *pdfPainter) setFill(c color.Color) bool {
+	setColor, ok := pdfFillColor(c)
+	if !ok {
+		return false
+	}
+
+	if alpha := toNRGBA(c).A; alpha != v.alpha {
+		i := slices.Index(v.alphas, alpha)
+		if i < 0 {
+			i = len(v.alphas)
+			v.alphas = append(v.alphas, alpha)
+		}
+		fmt.Fprintf(v.buf, "/GS%d gs\n", i)
+		v.alpha = alpha
+	}
+
+	v.buf.WriteString(setColor + "\n")
+	return true
+}
+
+func (v *pdfPainter) paint(f fill, evenOdd bool, draw func(p pathWriter)) {
+	if f.gradient != nil {
+		v.buf.WriteString("q\n")
+		draw(pdfPath{v.buf})
+		if evenOdd {
+			v.buf.WriteString("W* n\n")
+		} else {
+			v.buf.WriteString("W n\n")
+		}
+		fmt.Fprintf(v.buf, "/Sh%d sh\nQ\n", len(v.shadings))
+		v.shadings = append(v.shadings, shadingDict(f))
+		return
+	}
+
+	if !v.setFill(f.color) {
+		return
+	}
+
+	draw(pdfPath{v.buf})
+	if evenOdd {
+		v.buf.WriteString("f*\n")
+	} else {
+		v.buf.WriteString("f\n")
+	}
+}
+
+// shadingDict returns a shading dictionary drawing the gradient of f in
+// DeviceRGB, in the syntax shared by PDF and PostScript.
+func shadingDict(f fill) string {
+	rgb := func(c color.Color) string {
+		n := toNRGBA(c)
+		return fmt.Sprintf("[%s %s %s]",
+			formatNumber(float64(n.R)/0xff),
+			formatNumber(float64(n.G)/0xff),
+			formatNumber(float64(n.B)/0xff))
+	}
+
+	var functions, bounds, encode []string
+	for i := 1; i < len(f.stops); i++ {
+		functions = append(functions, fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 %s /C1 %s /N 1 >>",
+			rgb(f.stops[i-1].Color), rgb(f.stops[i].Color)))
+		encode = append(encode, "0 1")
+		if i < len(f.stops)-1 {
+			bounds = append(bounds, formatNumber(f.stops[i].Offset))
+		}
+	}
+
+	function := functions[0]
+	if len(functions) > 1 {
+		function = fmt.Sprintf("<< /FunctionType 3 /Domain [0 1] /Functions [%s] /Bounds [%s] /Encode [%s] >>",
+			strings.Join(functions, " "), strings.Join(bounds, " "), strings.Join(encode, " "))
+	}
+
+	var shadingType int
+	var coords []float64
+	switch f.gradient.Kind {
+	case RadialGradient:
+		cx, cy, r := f.radialCoords()
+		shadingType, coords = 3, []float64{cx, cy, 0, cx, cy, r}
+	default:
+		x0, y0, x1, y1 := f.linearCoords()
+		shadingType, coords = 2, []float64{x0, y0, x1, y1}
+	}
+
+	numbers := make([]string, len(coords))
+	for i, c := range coords {
+		numbers[i] = formatNumber(c)
+	}
+
+	return fmt.Sprintf("<< /ShadingType %d /ColorSpace /DeviceRGB /Coords [%s] /Function %s /Extend [true true] >>",
+		shadingType, strings.Join(numbers, " "), function)
+}
+
+type pdfPath struct {
+	buf *bytes.Buffer
+}
+
+func (p pdfPath) moveTo(x, y float64) {
+	fmt.Fprintf(p.buf, "%s %s m\n", formatNumber(x), formatNumber(y))
+}
+
+func (p pdfPath) lineTo(x, y float64) {
+	fmt.Fprintf(p.buf, "%s %s l\n", formatNumber(x), formatNumber(y))
+}
+
+func (p pdfPath) curveTo(x1, y1, x2, y2, x, y float64) {
+	fmt.Fprintf(p.buf, "%s %s %s %s %s %s c\n",
+		formatNumber(x1), formatNumber(y1),
+		formatNumber(x2), formatNumber(y2),
+		formatNumber(x), formatNumber(y))
+}
+
+func (p pdfPath) closePath() {
+	p.buf.WriteString("h\n")
+}
+
+func (p pdfPath) rect(x, y, w, h int) {
+	fmt.Fprintf(p.buf, "%d %d %d %d re\n", x, y, w, h)
+}
+
+func pdfStream(dict, content string) string {
+	return fmt.Sprintf("<< %s/Length %d >>\nstream\n%sendstream", dict, len(content), content)
+}
+
+// pdfFillColor returns the fill color operator for c. It returns false when c
+// is fully transparent.
+func pdfFillColor(c color.Color) (string, bool) {
+	if cmyk, ok := c.(color.CMYK); ok {
+		return fmt.Sprintf("%s %s %s %s k",
+			formatNumber(float64(cmyk.C)/0xff),
+			formatNumber(float64(cmyk.M)/0xff),
+			formatNumber(float64(cmyk.Y)/0xff),
+			formatNumber(float64(cmyk.K)/0xff)), true
+	}
+
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if n.A == 0 {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s %s %s rg",
+		formatNumber(float64(n.R)/0xff),
+		formatNumber(float64(n.G)/0xff),
+		formatNumber(float64(n.B)/0xff)), true
+}
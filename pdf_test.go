@@ -0,0 +1,80 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestWritePDF(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	err = q.WritePDF(buf, PDFOptions{Size: 40, PageWidth: 210, PageHeight: 297, X: 10, Y: 10})
+	assert.NoError(t, err)
+
+	pdf := buf.String()
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4"))
+	assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	assert.True(t, strings.Contains(pdf, "/MediaBox [0 0 595.2756 841.8898]"))
+
+	xref := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(pdf, -1)
+	assert.Equal(t, len(xref), 5)
+	for i, entry := range xref {
+		offset, err := strconv.Atoi(entry[1])
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(pdf[offset:], fmt.Sprintf("%d 0 obj", i+1)))
+	}
+
+	start := strings.Index(pdf, "startxref\n") + len("startxref\n")
+	end := strings.Index(pdf[start:], "\n")
+	offset, err := strconv.Atoi(pdf[start : start+end])
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(pdf[offset:], "xref"))
+}
+
+func TestWritePDFXObjectCMYK(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	q.WithColors(color.CMYK{C: 0xff, M: 0, Y: 0, K: 0x80}, color.Transparent)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, q.WritePDFXObject(buf, 25))
+
+	obj := buf.String()
+	assert.True(t, strings.Contains(obj, "/Subtype /Form"))
+	assert.True(t, strings.Contains(obj, "1 0 0 0.502 k"))
+	assert.False(t, strings.Contains(obj, " rg"))
+	assert.Error(t, q.WritePDFXObject(buf, 0))
+}
+
+func TestWritePDFXObjectOpacity(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	q.WithColors(color.Black, color.NRGBA{0xff, 0xff, 0xff, 0x80})
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, q.WritePDFXObject(buf, 25))
+
+	// The background is half opaque, then the modules are opaque again.
+	obj := buf.String()
+	assert.True(t, strings.Contains(obj, "/ExtGState << /GS0 << /ca 0.502 >> /GS1 << /ca 1 >> >>"))
+	background := strings.Index(obj, "/GS0 gs\n1 1 1 rg\n")
+	foreground := strings.Index(obj, "/GS1 gs\n0 0 0 rg\n")
+	assert.True(t, background >= 0 && foreground > background)
+	assert.Equal(t, strings.Count(obj, " gs\n"), 2)
+
+	buf.Reset()
+	q.WithColors(color.Black, color.White)
+	assert.NoError(t, q.WritePDFXObject(buf, 25))
+	assert.False(t, strings.Contains(buf.String(), "ExtGState"))
+}
@@ -0,0 +1,175 @@
+package qrcode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// WithPNGCompression sets the compression level of the PNG images of the
+// QRCode. The zero value, png.DefaultCompression, uses png.BestCompression.
+func (q *QRCode) WithPNGCompression(level png.CompressionLevel) *QRCode {
+	q.PNGCompression = level
+	return q
+}
+
+// pngCompression returns level, or png.BestCompression for the zero value.
+// QR Codes are small and compress well, so PNG output favours size unless
+// asked otherwise.
+func pngCompression(level png.CompressionLevel) png.CompressionLevel {
+	if level == png.DefaultCompression {
+		return png.BestCompression
+	}
+	return level
+}
+
+// pngSignature starts every PNG file.
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// pngChunkSize is the largest IDAT chunk written by writeBilevelPNG.
+const pngChunkSize = 1 << 16
+
+// PNG row filter types.
+const (
+	pngFilterNone = 0
+	pngFilterUp   = 2
+)
+
+// writeBilevelPNG writes bitmap laid out by l as a PNG with a bit depth of 1
+// and a palette of background and foreground.
+//
+// Rows are compressed as they are built, so memory use depends only on the
+// width of the image. A row repeating the one above is written with the Up
+// filter, as zeros, which compresses to almost nothing.
+func writeBilevelPNG(w io.Writer, bitmap [][]bool, l rasterLayout, foreground, background color.Color, level png.CompressionLevel) error {
+	header := make([]byte, 13)
+	binary.BigEndian.PutUint32(header[0:], uint32(l.size))
+	binary.BigEndian.PutUint32(header[4:], uint32(l.size))
+	header[8] = 1 // Bit depth.
+	header[9] = 3 // Paletted.
+
+	bg, fg := toNRGBA(background), toNRGBA(foreground)
+	palette := []byte{bg.R, bg.G, bg.B, fg.R, fg.G, fg.B}
+
+	if _, err := io.WriteString(w, pngSignature); err != nil {
+		return err
+	}
+	if err := writePNGChunk(w, "IHDR", header); err != nil {
+		return err
+	}
+	if err := writePNGChunk(w, "PLTE", palette); err != nil {
+		return err
+	}
+	if bg.A != 0xff || fg.A != 0xff {
+		if err := writePNGChunk(w, "tRNS", []byte{bg.A, fg.A}); err != nil {
+			return err
+		}
+	}
+
+	idat := &pngChunkWriter{w: w, name: "IDAT"}
+	z, err := zlib.NewWriterLevel(idat, zlibLevel(pngCompression(level)))
+	if err != nil {
+		return err
+	}
+
+	columns := make([]int, l.size)
+	for x := range columns {
+		columns[x] = l.module(x, len(bitmap))
+	}
+
+	row := make([]byte, 1+(l.size+7)/8)
+	repeat := make([]byte, len(row))
+	repeat[0] = pngFilterUp
+
+	previous := -2
+	for y := range l.size {
+		my := l.module(y, len(bitmap))
+		if my == previous {
+			if _, err := z.Write(repeat); err != nil {
+				return err
+			}
+			continue
+		}
+		previous = my
+
+		clear(row)
+		row[0] = pngFilterNone
+		if my >= 0 {
+			for x, mx := range columns {
+				if mx >= 0 && bitmap[my][mx] {
+					row[1+x/8] |= 0x80 >> (x % 8)
+				}
+			}
+		}
+
+		if _, err := z.Write(row); err != nil {
+			return err
+		}
+	}
+
+	if err := z.Close(); err != nil {
+		return err
+	}
+	if err := idat.flush(); err != nil {
+		return err
+	}
+
+	return writePNGChunk(w, "IEND", nil)
+}
+
+// zlibLevel returns the zlib compression level matching level, as used by
+// png.Encoder.
+func zlibLevel(level png.CompressionLevel) int {
+	switch level {
+	case png.NoCompression:
+		return zlib.NoCompression
+	case png.BestSpeed:
+		return zlib.BestSpeed
+	case png.BestCompression:
+		return zlib.BestCompression
+	default:
+		return zlib.DefaultCompression
+	}
+}
+
+// writePNGChunk writes a PNG chunk with its length and checksum.
+func writePNGChunk(w io.Writer, name string, data []byte) error {
+	chunk := make([]byte, 0, len(data)+12)
+	chunk = binary.BigEndian.AppendUint32(chunk, uint32(len(data)))
+	chunk = append(chunk, name...)
+	chunk = append(chunk, data...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	_, err := w.Write(chunk)
+	return err
+}
+
+// pngChunkWriter splits the data written to it into chunks of at most
+// pngChunkSize bytes.
+type pngChunkWriter struct {
+	w    io.Writer
+	name string
+	buf  bytes.Buffer
+}
+
+func (c *pngChunkWriter) Write(p []byte) (int, error) {
+	n, _ := c.buf.Write(p)
+	for c.buf.Len() >= pngChunkSize {
+		if err := writePNGChunk(c.w, c.name, c.buf.Next(pngChunkSize)); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// flush writes the remaining data as a final chunk.
+func (c *pngChunkWriter) flush() error {
+	if c.buf.Len() == 0 {
+		return nil
+	}
+	return writePNGChunk(c.w, c.name, c.buf.Next(c.buf.Len()))
+}
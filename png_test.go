@@ -0,0 +1,147 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestWriteBilevelPNG(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	m := q.Matrix(0)
+
+	for _, size := range []int{m.size, 100, 256, -3} {
+		l := imageLayout(size, m.size)
+		for _, level := range []png.CompressionLevel{png.DefaultCompression, png.NoCompression, png.BestSpeed, png.BestCompression} {
+			var buf bytes.Buffer
+			assert.NoError(t, writeBilevelPNG(&buf, m.dark, l, navy, color.White, level))
+
+			img, err := png.Decode(&buf)
+			assert.NoError(t, err)
+			want := plainImage(m.dark, l, RasterNRGBA, navy, color.White)
+			assert.Equal(t, img.Bounds(), want.Bounds())
+
+			for y := range l.size {
+				for x := range l.size {
+					if toNRGBA(img.At(x, y)) != toNRGBA(want.At(x, y)) {
+						t.Fatalf("size %d level %d: pixel %d, %d differs", size, level, x, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestWriteBilevelPNGTransparency(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithTransparentBackground().WithRasterMode(RasterPaletted)
+
+	img, err := png.Decode(bytes.NewReader(q.PNG(-2)))
+	assert.NoError(t, err)
+
+	p, ok := img.(*image.Paletted)
+	assert.True(t, ok)
+	assert.Equal(t, len(p.Palette), 2)
+	assert.Equal(t, toNRGBA(img.At(0, 0)).A, uint8(0))
+}
+
+func TestPNGCompression(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	stored := len(q.WithPNGCompression(png.NoCompression).PNG(1000))
+	compressed := len(q.WithPNGCompression(png.BestCompression).PNG(1000))
+	assert.True(t, compressed < stored)
+
+	// The zero value compresses as much as possible, streamed or not.
+	assert.Equal(t, len(q.WithPNGCompression(png.DefaultCompression).PNG(1000)), compressed)
+
+	q.WithModuleShape(ShapeCircle)
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	assert.NoError(t, encoder.Encode(&buf, q.Image(300)))
+	assert.Equal(t, q.PNG(300), buf.Bytes())
+
+	buf.Reset()
+	assert.NoError(t, q.WriteFormat(&buf, "png", FormatOptions{Size: 300}))
+	assert.Equal(t, q.PNG(300), buf.Bytes())
+}
+
+// chunkCounter counts the IDAT chunks written to it.
+type chunkCounter struct {
+	idat int
+}
+
+func (c *chunkCounter) Write(p []byte) (int, error) {
+	if len(p) >= 8 && string(p[4:8]) == "IDAT" {
+		c.idat++
+	}
+	return len(p), nil
+}
+
+func TestWriteBilevelPNGChunks(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	m := q.Matrix(0)
+
+	// Uncompressed rows of 1250 bytes fill several chunks.
+	var c chunkCounter
+	assert.NoError(t, writeBilevelPNG(&c, m.dark, imageLayout(10000, m.size), color.Black, color.White, png.NoCompression))
+	assert.True(t, c.idat > 100)
+}
+
+func benchmarkQRCode(b *testing.B) *QRCode {
+	q, err := New(i9siDomain, Medium)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return q
+}
+
+// BenchmarkPNGImage encodes a 4000 pixel PNG through an image.Paletted, as
+// PNG did before streaming.
+func BenchmarkPNGImage(b *testing.B) {
+	q := benchmarkQRCode(b)
+	m := q.Matrix(0)
+	b.ReportAllocs()
+
+	for b.Loop() {
+		img := plainImage(m.dark, imageLayout(4000, m.size), RasterPaletted, color.Black, color.White)
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&bytes.Buffer{}, img); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPNGStream(b *testing.B) {
+	q := benchmarkQRCode(b)
+	m := q.Matrix(0)
+	b.ReportAllocs()
+
+	for b.Loop() {
+		err := writeBilevelPNG(&bytes.Buffer{}, m.dark, imageLayout(4000, m.size), color.Black, color.White, png.DefaultCompression)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPNGStreamBestSpeed(b *testing.B) {
+	q := benchmarkQRCode(b)
+	m := q.Matrix(0)
+	b.ReportAllocs()
+
+	for b.Loop() {
+		err := writeBilevelPNG(&bytes.Buffer{}, m.dark, imageLayout(4000, m.size), color.Black, color.White, png.BestSpeed)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Unit is a unit of physical length.
+type Unit int
+
+const (
+	Millimetre Unit = iota
+	Inch
+)
+
+const millimetresPerInch = 25.4
+
+// millimetres converts v in unit u to millimetres.
+func (u Unit) millimetres(v float64) float64 {
+	if u == Inch {
+		return v * millimetresPerInch
+	}
+	return v
+}
+
+// DefaultDPI is the default print resolution, in dots per inch.
+const DefaultDPI = 300
+
+// DefaultMinModuleSize is the default smallest printed module size, in
+// millimetres, before a warning is raised.
+const DefaultMinModuleSize = 0.33
+
+// ErrModuleTooSmall is returned, or reported to QRCode.OnWarning when it is
+// set, when a QRCode is printed with modules smaller than the minimum module
+// size.
+var ErrModuleTooSmall = errors.New("module size is below the minimum")
+
+// PrintOptions sizes a raster QRCode for print.
+type PrintOptions struct {
+	// Size is the width of the printed QR Code, including its quiet
+	// zone, in Unit. A framed QR Code is fitted with its frame in a square
+	// Size wide.
+	Size float64
+	Unit Unit
+
+	// DPI is the print resolution in dots per inch. Defaults to
+	// DefaultDPI.
+	DPI int
+
+	// MinModuleSize is the smallest module size, in millimetres, printed
+	// without a warning. Defaults to DefaultMinModuleSize.
+	MinModuleSize float64
+}
+
+func (opts *PrintOptions) setDefaults() error {
+	if opts.DPI == 0 {
+		opts.DPI = DefaultDPI
+	}
+	if opts.MinModuleSize == 0 {
+		opts.MinModuleSize = DefaultMinModuleSize
+	}
+
+	if opts.Size <= 0 || opts.DPI < 0 || opts.MinModuleSize < 0 {
+		return errors.New("invalid print size or resolution")
+	}
+	return nil
+}
+
+// pixels returns the width of the printed QR Code in pixels.
+func (opts PrintOptions) pixels() int {
+	return int(opts.Unit.millimetres(opts.Size) / millimetresPerInch * float64(opts.DPI))
+}
+
+// PrintImage returns an image of the QRCode opts.Size wide at opts.DPI. The
+// module size is the largest whole number of pixels that fits, with the
+// symbol centred on the background.
+//
+// If the printed modules are smaller than opts.MinModuleSize, PrintImage
+// returns an error wrapping ErrModuleTooSmall. When OnWarning is set, the
+// error is reported there instead and the QRCode is still drawn.
+func (q *QRCode) PrintImage(opts PrintOptions) (image.Image, error) {
+	if err := opts.setDefaults(); err != nil {
+		return nil, err
+	}
+	if err := q.checkColors(); err != nil {
+		return nil, err
+	}
+
+	img, pixels := q.imageFit(opts.pixels())
+
+	moduleSize := float64(pixels) / float64(opts.DPI) * millimetresPerInch
+	if moduleSize < opts.MinModuleSize {
+		err := fmt.Errorf("%w: %.3gmm at %d dpi (minimum is %gmm)",
+			ErrModuleTooSmall, moduleSize, opts.DPI, opts.MinModuleSize)
+		if q.OnWarning == nil {
+			return nil, err
+		}
+		q.warn(err)
+	}
+
+	return img, nil
+}
+
+// WritePrintPNG writes a PNG image of the QRCode sized for print, with a
+// pHYs chunk recording the resolution.
+func (q *QRCode) WritePrintPNG(out io.Writer, opts PrintOptions) error {
+	img, err := q.PrintImage(opts)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	encoder := png.Encoder{CompressionLevel: pngCompression(q.PNGCompression)}
+	if err := encoder.Encode(buf, img); err != nil {
+		return err
+	}
+
+	_, err = q.pngWriter(out).Write(withPNGResolution(buf.Bytes(), opts.DPI))
+	return err
+}
+
+// WritePrintJPEG writes a JPEG image of the QRCode sized for print, with a
+// JFIF header recording the resolution. A nil jpeg.Options uses the default
+// quality.
+func (q *QRCode) WritePrintJPEG(out io.Writer, opts PrintOptions, jpegOpts *jpeg.Options) error {
+	img, err := q.PrintImage(opts)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, img, jpegOpts); err != nil {
+		return err
+	}
+
+	_, err = out.Write(withJPEGResolution(buf.Bytes(), opts.DPI))
+	return err
+}
+
+// pngHeaderLength is the length of the PNG signature and the IHDR chunk.
+const pngHeaderLength = 8 + 4 + 4 + 13 + 4
+
+// withPNGResolution returns the PNG data with a pHYs chunk for dpi inserted
+// after the IHDR chunk.
+func withPNGResolution(data []byte, dpi int) []byte {
+	pixelsPerMetre := uint32(float64(dpi)/millimetresPerInch*1000 + 0.5)
+
+	chunk := make([]byte, 9)
+	binary.BigEndian.PutUint32(chunk[0:], pixelsPerMetre)
+	binary.BigEndian.PutUint32(chunk[4:], pixelsPerMetre)
+	chunk[8] = 1 // The unit is the metre.
+
+	return insertPNGChunk(data, "pHYs", chunk)
+}
+
+// insertPNGChunk returns the PNG data with a chunk inserted after the IHDR
+// chunk.
+func insertPNGChunk(data []byte, name string, chunk []byte) []byte {
+	out := make([]byte, 0, len(data)+len(chunk)+12)
+	out = append(out, data[:pngHeaderLength]...)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(chunk)))
+
+	start := len(out)
+	out = append(out, name...)
+	out = append(out, chunk...)
+	out = binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(out[start:]))
+
+	return append(out, data[pngHeaderLength:]...)
+}
+
+// withJPEGResolution returns the JPEG data with a JFIF APP0 segment for dpi
+// inserted after the start of image marker.
+func withJPEGResolution(data []byte, dpi int) []byte {
+	density := uint16(min(dpi, 0xffff))
+
+	app0 := []byte{0xff, 0xe0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 2, 1}
+	app0 = binary.BigEndian.AppendUint16(app0, density)
+	app0 = binary.BigEndian.AppendUint16(app0, density)
+	app0 = append(app0, 0, 0)
+
+	out := make([]byte, 0, len(data)+len(app0))
+	out = append(out, data[:2]...)
+	out = append(out, app0...)
+	return append(out, data[2:]...)
+}
@@ -0,0 +1,79 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestPrintImage(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	var warnings []error
+	q.OnWarning = func(err error) { warnings = append(warnings, err) }
+
+	img, err := q.PrintImage(PrintOptions{Size: 1, Unit: Inch, DPI: 300})
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dx(), 300)
+	assert.Equal(t, len(warnings), 0)
+
+	img, err = q.PrintImage(PrintOptions{Size: 10, DPI: 600})
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dx(), 236)
+	assert.Equal(t, len(warnings), 1)
+	assert.True(t, errors.Is(warnings[0], ErrModuleTooSmall))
+
+	_, err = q.PrintImage(PrintOptions{})
+	assert.Error(t, err)
+
+	// Without OnWarning, modules that are too small are an error.
+	q.OnWarning = nil
+	img, err = q.PrintImage(PrintOptions{Size: 10, DPI: 600})
+	assert.True(t, errors.Is(err, ErrModuleTooSmall))
+	assert.True(t, img == nil)
+
+	var buf bytes.Buffer
+	assert.True(t, errors.Is(q.WritePrintPNG(&buf, PrintOptions{Size: 10, DPI: 600}), ErrModuleTooSmall))
+	assert.Equal(t, buf.Len(), 0)
+}
+
+func TestWritePrintPNG(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WritePrintPNG(&buf, PrintOptions{Size: 30, DPI: 300}))
+	data := buf.Bytes()
+
+	assert.Equal(t, string(data[pngHeaderLength+4:pngHeaderLength+8]), "pHYs")
+	ppm := binary.BigEndian.Uint32(data[pngHeaderLength+8:])
+	assert.Equal(t, ppm, uint32(11811))
+
+	img, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dx(), 354)
+}
+
+func TestWritePrintJPEG(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WritePrintJPEG(&buf, PrintOptions{Size: 1, Unit: Inch, DPI: 600}, nil))
+	data := buf.Bytes()
+
+	assert.Equal(t, data[2:4], []byte{0xff, 0xe0})
+	assert.Equal(t, string(data[6:11]), "JFIF\x00")
+	assert.Equal(t, data[13], byte(1))
+	assert.Equal(t, binary.BigEndian.Uint16(data[14:]), uint16(600))
+
+	img, err := jpeg.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dx(), 600)
+}
@@ -9,7 +9,7 @@ import (
 	"image/png"
 	"io"
 	"log"
-	"os"
+	"strings"
 
 	"github.com/i9si-sistemas/bitset"
 	"github.com/i9si-sistemas/reedsolomon"
@@ -22,6 +22,23 @@ type QRCode struct {
 	ForegroundColor color.Color
 	BackgroundColor color.Color
 	DisableBorder   bool
+	ModuleShape     ModuleShape
+	FinderStyle     FinderStyle
+	Gradient        *Gradient
+	DataColor       color.Color
+	FinderColor     color.Color
+	AlignmentColor  color.Color
+	RasterMode      RasterMode
+	OnWarning       func(error)
+	ContrastPolicy  ContrastPolicy
+	MinContrast     float64
+	Inverted        bool
+	PNGCompression  png.CompressionLevel
+	pngGenerator    string
+	logo            *logo
+	frame           *FrameOptions
+	targetImage     image.Image
+	target          [][]bool
 	encoder         *dataEncoder
 	version         qrCodeVersion
 	data            *bitset.Bitset
@@ -135,7 +152,11 @@ func Encode(content string, level RecoveryLevel, size int) ([]byte, error) {
 		return nil, err
 	}
 
-	return q.PNG(size), nil
+	buf := new(bytes.Buffer)
+	if err := q.writePNG(buf, size); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // Write writes a PNG image of the QRCode
@@ -175,52 +196,43 @@ func (q *QRCode) Bitmap() [][]bool {
 	return q.symbol.bitmap()
 }
 
-// Image returns an image.Image of the QRCode.
-func (q *QRCode) Image(size int) image.Image {
-	q.encode()
-
-	realSize := q.symbol.size
+// NoQuietZone can be used as a quiet zone size to render the QRCode without
+// a quiet zone.
+const NoQuietZone = -1
 
-	if size < 0 {
-		size = size * -1 * realSize
-	}
+// bitmapWithQuietZone returns the bitmap of the QRCode with a quiet zone of
+// quietZone modules. Zero keeps the default quiet zone and NoQuietZone
+// removes it.
+func (q *QRCode) bitmapWithQuietZone(quietZone int) [][]bool {
+	q.encode()
 
-	if size < realSize {
-		size = realSize
+	switch {
+	case quietZone == 0:
+		return q.symbol.bitmap()
+	case quietZone < 0:
+		return q.symbol.bitmapWithQuietZone(0)
+	default:
+		return q.symbol.bitmapWithQuietZone(quietZone)
 	}
+}
 
-	rect := image.Rectangle{Min: image.Point{0, 0}, Max: image.Point{size, size}}
-
-	p := color.Palette([]color.Color{q.BackgroundColor, q.ForegroundColor})
-	img := image.NewPaletted(rect, p)
-	fgClr := uint8(img.Palette.Index(q.ForegroundColor))
-
-	bitmap := q.symbol.bitmap()
+// Image returns an image.Image of the QRCode.
+func (q *QRCode) Image(size int) image.Image {
+	q.encode()
 
-	modulesPerPixel := float64(realSize) / float64(size)
-	for y := range size {
-		y2 := int(float64(y) * modulesPerPixel)
-		for x := range size {
-			x2 := int(float64(x) * modulesPerPixel)
-			v := bitmap[y2][x2]
-			if v {
-				pos := img.PixOffset(x, y)
-				img.Pix[pos] = fgClr
-			}
-		}
+	if q.frame != nil {
+		return q.framedImage(size)
 	}
 
-	return img
+	return q.rasterize(imageLayout(size, q.symbol.size))
 }
 
-// PNG returns a PNG image of the QRCode.
+// PNG returns a PNG image of the QRCode, or nil if it cannot be encoded. Use
+// Write to get the error.
 func (q *QRCode) PNG(size int) []byte {
-	img := q.Image(size)
 	buf := new(bytes.Buffer)
-	encoder := png.Encoder{CompressionLevel: png.BestCompression}
-	err := encoder.Encode(buf, img)
-	if err != nil {
-		return q.PNG(len(buf.Bytes()))
+	if err := q.writePNG(buf, size); err != nil {
+		return nil
 	}
 
 	return buf.Bytes()
@@ -228,16 +240,29 @@ func (q *QRCode) PNG(size int) []byte {
 
 // Write writes a PNG image of the QRCode.
 func (q *QRCode) Write(size int, out io.Writer) error {
-	png := q.PNG(size)
-	if _, err := out.Write(png); err != nil {
+	if err := q.checkColors(); err != nil {
 		return err
 	}
-	return nil
+
+	return q.writePNG(out, size)
+}
+
+// writePNG writes a PNG image of the QRCode. Plain QRCodes are streamed a row
+// at a time with a bit depth of 1.
+func (q *QRCode) writePNG(out io.Writer, size int) error {
+	out = q.pngWriter(out)
+	if q.isPlain() {
+		return q.pngRenderer(size).Render(out, q.Matrix(0))
+	}
+
+	encoder := png.Encoder{CompressionLevel: pngCompression(q.PNGCompression)}
+	return encoder.Encode(out, q.Image(size))
 }
 
-// WriteFile writes a PNG image of the QRCode.
+// WriteFile writes an image of the QRCode in the format registered for the
+// extension of filename, or PNG if there is none.
 func (q *QRCode) WriteFile(size int, filename string) error {
-	return os.WriteFile(filename, q.PNG(size), os.FileMode(0644))
+	return q.writeFormatFile(filename, FormatOptions{Size: size})
 }
 
 const DefaultFileSize = 256
@@ -251,9 +276,14 @@ func (q *QRCode) encode() {
 	numTerminatorBits := q.version.numTerminatorBitsRequired(q.data.Len())
 
 	q.addTerminatorBits(numTerminatorBits)
+	if q.target != nil && q.symbol == nil {
+		q.encodeForTarget()
+		return
+	}
+
 	q.addPadding()
 
-	encoded := q.encodeBlocks()
+	encoded := q.encodeBlocks(q.data)
 
 	const numMasks int = 8
 	penalty := 0
@@ -280,7 +310,9 @@ func (q *QRCode) encode() {
 	}
 }
 
-// WithColors sets the foreground and background colors of the QRCode.
+// WithColors sets the foreground and background colors of the QRCode. The
+// colors are checked when the QRCode is written; use SetColors to check them
+// immediately.
 func (q *QRCode) WithColors(foreground, background color.Color) *QRCode {
 	q.ForegroundColor = foreground
 	q.BackgroundColor = background
@@ -293,11 +325,20 @@ func (q *QRCode) WithNoBorder() *QRCode {
 	return q
 }
 
+// warn reports err to OnWarning, and drops it when OnWarning is nil. Warnings
+// are problems that do not stop the QRCode from being drawn but may make it
+// hard to scan.
+func (q *QRCode) warn(err error) {
+	if q.OnWarning != nil {
+		q.OnWarning(err)
+	}
+}
+
 func (q *QRCode) addTerminatorBits(numTerminatorBits int) {
 	q.data.AppendNumBools(numTerminatorBits, false)
 }
 
-func (q *QRCode) encodeBlocks() *bitset.Bitset {
+func (q *QRCode) encodeBlocks(data *bitset.Bitset) *bitset.Bitset {
 	type dataBlock struct {
 		data          *bitset.Bitset
 		ecStartOffset int
@@ -315,7 +356,7 @@ func (q *QRCode) encodeBlocks() *bitset.Bitset {
 			end = start + b.numDataCodewords*8
 
 			numErrorCodewords := b.numCodewords - b.numDataCodewords
-			block[blockID].data = reedsolomon.Encode(q.data.Substr(start, end), numErrorCodewords)
+			block[blockID].data = reedsolomon.Encode(data.Substr(start, end), numErrorCodewords)
 			block[blockID].ecStartOffset = end - start
 
 			blockID++
@@ -388,53 +429,14 @@ func (q *QRCode) addPadding() {
 
 // ToString returns a string representation of the QRCode.
 func (q *QRCode) ToString(inverseColor bool) string {
-	bits := q.Bitmap()
-	var buf bytes.Buffer
-	for y := range bits {
-		for x := range bits[y] {
-			if bits[y][x] != inverseColor {
-				buf.WriteString("  ")
-			} else {
-				buf.WriteString("██")
-			}
-		}
-		buf.WriteString("\n")
-	}
+	var buf strings.Builder
+	TextRenderer{Inverse: inverseColor}.Render(&buf, q.Matrix(0))
 	return buf.String()
 }
 
 // ToSmallString returns a small string representation of the QRCode.
 func (q *QRCode) ToSmallString(inverseColor bool) string {
-	bits := q.Bitmap()
-	var buf bytes.Buffer
-	for y := 0; y < len(bits)-1; y += 2 {
-		for x := range bits[y] {
-			if bits[y][x] == bits[y+1][x] {
-				if bits[y][x] != inverseColor {
-					buf.WriteString(" ")
-				} else {
-					buf.WriteString("█")
-				}
-			} else {
-				if bits[y][x] != inverseColor {
-					buf.WriteString("▄")
-				} else {
-					buf.WriteString("▀")
-				}
-			}
-		}
-		buf.WriteString("\n")
-	}
-	if len(bits)%2 == 1 {
-		y := len(bits) - 1
-		for x := range bits[y] {
-			if bits[y][x] != inverseColor {
-				buf.WriteString(" ")
-			} else {
-				buf.WriteString("▀")
-			}
-		}
-		buf.WriteString("\n")
-	}
+	var buf strings.Builder
+	TextRenderer{Inverse: inverseColor, Small: true}.Render(&buf, q.Matrix(0))
 	return buf.String()
 }
@@ -0,0 +1,284 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// RasterMode is the type of image returned by QRCode.Image for QRCodes drawn
+// with square modules of a single color. Other QRCodes are anti-aliased, and
+// always drawn into an image.NRGBA unless the mode is RasterGray.
+type RasterMode int
+
+const (
+	// RasterPaletted draws into an image.Paletted of the background and
+	// foreground colors.
+	RasterPaletted RasterMode = iota
+	// RasterNRGBA draws into an image.NRGBA, keeping the alpha of
+	// transparent and semi-transparent colors.
+	RasterNRGBA
+	// RasterGray draws into an image.Gray, ignoring alpha. Black and white
+	// QRCodes keep only the values 0 and 0xff.
+	RasterGray
+)
+
+// WithRasterMode sets the type of image drawn by the QRCode.
+func (q *QRCode) WithRasterMode(mode RasterMode) *QRCode {
+	q.RasterMode = mode
+	return q
+}
+
+// WithTransparentBackground makes the background and quiet zone of the
+// QRCode transparent, and draws it into an image.NRGBA.
+func (q *QRCode) WithTransparentBackground() *QRCode {
+	q.BackgroundColor = color.Transparent
+	q.RasterMode = RasterNRGBA
+	return q
+}
+
+// ImageWithModuleSize returns an image.Image of the QRCode in which every
+// module is exactly moduleSize pixels wide.
+func (q *QRCode) ImageWithModuleSize(moduleSize int) image.Image {
+	q.encode()
+
+	moduleSize = max(moduleSize, 1)
+	if q.frame != nil {
+		return q.framedImage(-moduleSize)
+	}
+
+	return q.rasterize(rasterLayout{
+		size:    moduleSize * q.symbol.size,
+		modules: 1,
+		pixels:  moduleSize,
+	})
+}
+
+// ImageFit returns a size x size image.Image of the QRCode drawn with the
+// largest whole number of pixels per module that fits, centred on the
+// background instead of stretched. The image is never smaller than one pixel
+// per module. A framed QRCode is fitted with its frame.
+func (q *QRCode) ImageFit(size int) image.Image {
+	img, _ := q.imageFit(size)
+	return img
+}
+
+// imageFit returns the image of ImageFit and its number of pixels per module.
+func (q *QRCode) imageFit(size int) (image.Image, int) {
+	q.encode()
+
+	if q.frame != nil {
+		img, moduleSize := q.framedImageFit(size)
+		if q.RasterMode == RasterGray {
+			return toGray(img), moduleSize
+		}
+		return img, moduleSize
+	}
+
+	realSize := q.symbol.size
+	size = max(size, realSize)
+	moduleSize := size / realSize
+
+	return q.rasterize(rasterLayout{
+		size:    size,
+		offset:  (size - moduleSize*realSize) / 2,
+		modules: 1,
+		pixels:  moduleSize,
+	}), moduleSize
+}
+
+// rasterLayout places the symbol in a size x size image. Pixel p lies in
+// module (p-offset)*modules/pixels.
+type rasterLayout struct {
+	size            int
+	offset          int
+	modules, pixels int
+}
+
+// imageLayout returns the layout of an image of size pixels, stretching the
+// symbol over the whole image. A negative size is a number of pixels per
+// module. The image is never smaller than one pixel per module.
+func imageLayout(size, symbolSize int) rasterLayout {
+	if size < 0 {
+		size = size * -1 * symbolSize
+	}
+
+	if size < symbolSize {
+		size = symbolSize
+	}
+
+	return rasterLayout{size: size, modules: symbolSize, pixels: size}
+}
+
+// module returns the module pixel p lies in, or -1 outside of the symbol.
+func (l rasterLayout) module(p, symbolSize int) int {
+	if p < l.offset {
+		return -1
+	}
+	if m := (p - l.offset) * l.modules / l.pixels; m < symbolSize {
+		return m
+	}
+	return -1
+}
+
+// point returns the position in modules of the point at p pixels.
+func (l rasterLayout) point(p float64) float64 {
+	return (p - float64(l.offset)) * float64(l.modules) / float64(l.pixels)
+}
+
+// rasterize draws the QRCode into an image of the type set by RasterMode.
+func (q *QRCode) rasterize(l rasterLayout) image.Image {
+	if !q.isPlain() {
+		img := q.antialiasedImage(l, toNRGBA(q.BackgroundColor))
+		if q.RasterMode == RasterGray {
+			return toGray(img)
+		}
+		return img
+	}
+
+	return plainImage(q.symbol.bitmap(), l, q.RasterMode, q.ForegroundColor, q.BackgroundColor)
+}
+
+// plainImage draws bitmap into an image of the given mode, with each pixel
+// taking the color of the module it falls in.
+func plainImage(bitmap [][]bool, l rasterLayout, mode RasterMode, foreground, background color.Color) image.Image {
+	rect := image.Rect(0, 0, l.size, l.size)
+
+	forEachDark := func(set func(x, y int)) {
+		for y := range l.size {
+			y2 := l.module(y, len(bitmap))
+			if y2 < 0 {
+				continue
+			}
+			for x := range l.size {
+				if x2 := l.module(x, len(bitmap)); x2 >= 0 && bitmap[y2][x2] {
+					set(x, y)
+				}
+			}
+		}
+	}
+
+	// Dark modules are painted over the background, so a semi-transparent
+	// foreground does not let the page show through.
+	foreground = over(toNRGBA(foreground), toNRGBA(background))
+
+	switch mode {
+	case RasterNRGBA:
+		img := image.NewNRGBA(rect)
+		draw.Draw(img, rect, image.NewUniform(toNRGBA(background)), image.Point{}, draw.Src)
+		fg := foreground.(color.NRGBA)
+		forEachDark(func(x, y int) { img.SetNRGBA(x, y, fg) })
+		return img
+	case RasterGray:
+		img := image.NewGray(rect)
+		draw.Draw(img, rect, image.NewUniform(color.GrayModel.Convert(background)), image.Point{}, draw.Src)
+		fg := color.GrayModel.Convert(foreground).(color.Gray)
+		forEachDark(func(x, y int) { img.SetGray(x, y, fg) })
+		return img
+	default:
+		// The foreground is always index 1, even if the two colors are
+		// equal or the palette would map it to the background.
+		p := color.Palette{background, foreground}
+		img := image.NewPaletted(rect, p)
+		forEachDark(func(x, y int) { img.Pix[img.PixOffset(x, y)] = 1 })
+		return img
+	}
+}
+
+// toGray converts img to grayscale, ignoring alpha.
+func toGray(img *image.NRGBA) *image.Gray {
+	gray := image.NewGray(img.Bounds())
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			c := img.NRGBAAt(x, y)
+			c.A = 0xff
+			gray.SetGray(x, y, color.GrayModel.Convert(c).(color.Gray))
+		}
+	}
+	return gray
+}
+
+// rasterSamples is the number of samples per pixel, in each direction, used
+// to anti-alias shaped modules.
+const rasterSamples = 4
+
+// antialiasedImage draws the QRCode over background, averaging several
+// samples of the painted colors for every pixel.
+func (q *QRCode) antialiasedImage(l rasterLayout, background color.NRGBA) *image.NRGBA {
+	m := q.Matrix(0)
+	paint := q.painter(m, background)
+	img := image.NewNRGBA(image.Rect(0, 0, l.size, l.size))
+
+	for y := range l.size {
+		for x := range l.size {
+			var sum colorSum
+			for sy := range rasterSamples {
+				fy := l.point(float64(y) + (float64(sy)+0.5)/rasterSamples)
+				for sx := range rasterSamples {
+					fx := l.point(float64(x) + (float64(sx)+0.5)/rasterSamples)
+					if fx < 0 || fy < 0 || fx >= float64(m.size) || fy >= float64(m.size) {
+						sum.add(background)
+						continue
+					}
+					sum.add(paint(fx, fy))
+				}
+			}
+
+			img.SetNRGBA(x, y, sum.average())
+		}
+	}
+
+	return img
+}
+
+// painter returns a function giving the color of the QRCode over background
+// at a point in module coordinates.
+func (q *QRCode) painter(m *ModuleMatrix, background color.NRGBA) func(x, y float64) color.NRGBA {
+	eyes := q.eyes(m)
+
+	var fills [RoleVersion + 1]fill
+	for role := range fills {
+		fills[role] = q.regionFill(m, ModuleRole(role))
+	}
+
+	var logo func(x, y float64) (color.NRGBA, bool)
+	if q.logo != nil {
+		logo = q.logo.painter(m, background)
+	}
+
+	return func(x, y float64) color.NRGBA {
+		if logo != nil {
+			if c, ok := logo(x, y); ok {
+				return c
+			}
+		}
+
+		for i := range eyes {
+			if f, ok := eyes[i].fillAt(x, y); ok {
+				if f == nil {
+					return background
+				}
+				return over(f.at(x, y), background)
+			}
+		}
+
+		mx, my := int(x), int(y)
+		if m.covers(q.ModuleShape, mx, my, x-float64(mx), y-float64(my)) {
+			return over(fills[m.role[my][mx]].at(x, y), background)
+		}
+		return background
+	}
+}
+
+// isPlain reports whether the QRCode is drawn with square modules of a single
+// color, which allows a two color palette.
+func (q *QRCode) isPlain() bool {
+	return q.ModuleShape == ShapeSquare &&
+		q.FinderStyle.isDefault() &&
+		q.Gradient == nil &&
+		q.DataColor == nil &&
+		q.FinderColor == nil &&
+		q.AlignmentColor == nil &&
+		q.logo == nil &&
+		q.frame == nil
+}
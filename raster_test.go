@@ -0,0 +1,137 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestPalettedImageSameColors(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	// Both colors map to the same palette index.
+	grey := color.RGBA{0x80, 0x80, 0x80, 0xff}
+	q.WithColors(grey, grey)
+
+	img, ok := q.Image(-1).(*image.Paletted)
+	assert.True(t, ok)
+
+	bitmap := q.Bitmap()
+	for y, row := range bitmap {
+		for x, dark := range row {
+			want := uint8(0)
+			if dark {
+				want = 1
+			}
+			assert.Equal(t, img.ColorIndexAt(x, y), want)
+		}
+	}
+}
+
+func TestNRGBAImage(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	fg := color.NRGBA{0x00, 0x20, 0x60, 0xc0}
+	q.WithColors(fg, color.White).WithTransparentBackground()
+
+	img, ok := q.Image(-2).(*image.NRGBA)
+	assert.True(t, ok)
+	assert.Equal(t, img.NRGBAAt(0, 0), color.NRGBA{})
+	assert.Equal(t, img.NRGBAAt(4*2, 4*2), fg)
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	decoded, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, toNRGBA(decoded.At(9, 9)), fg)
+}
+
+func TestSemiTransparentForeground(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithColors(color.NRGBA{0, 0, 0, 0x80}, color.White).WithRasterMode(RasterNRGBA)
+
+	// Dark modules are composited over the opaque background.
+	want := color.NRGBA{0x7f, 0x7f, 0x7f, 0xff}
+	img, ok := q.Image(-2).(*image.NRGBA)
+	assert.True(t, ok)
+	assert.Equal(t, img.NRGBAAt(4*2, 4*2), want)
+
+	q.WithModuleShape(ShapeCircle)
+	img, ok = q.Image(-8).(*image.NRGBA)
+	assert.True(t, ok)
+	assert.Equal(t, img.NRGBAAt(4*8+4, 4*8+4), want)
+}
+
+func TestGrayImage(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithRasterMode(RasterGray)
+
+	img, ok := q.Image(-1).(*image.Gray)
+	assert.True(t, ok)
+
+	bitmap := q.Bitmap()
+	for y, row := range bitmap {
+		for x, dark := range row {
+			want := color.Gray{0xff}
+			if dark {
+				want = color.Gray{0}
+			}
+			assert.Equal(t, img.GrayAt(x, y), want)
+		}
+	}
+
+	q.WithModuleShape(ShapeCircle)
+	_, ok = q.Image(-4).(*image.Gray)
+	assert.True(t, ok)
+}
+
+func TestImageWithModuleSize(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	const moduleSize = 7
+	bitmap := q.Bitmap()
+	img, ok := q.ImageWithModuleSize(moduleSize).(*image.Paletted)
+	assert.True(t, ok)
+	assert.Equal(t, img.Bounds().Dx(), len(bitmap)*moduleSize)
+
+	for y := range img.Bounds().Dy() {
+		for x := range img.Bounds().Dx() {
+			dark := img.ColorIndexAt(x, y) == 1
+			assert.Equal(t, dark, bitmap[y/moduleSize][x/moduleSize])
+		}
+	}
+}
+
+func TestImageFit(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	bitmap := q.Bitmap()
+	size := len(bitmap)*3 + 5
+	img, ok := q.ImageFit(size).(*image.Paletted)
+	assert.True(t, ok)
+	assert.Equal(t, img.Bounds().Dx(), size)
+
+	for y := range size {
+		for x := range size {
+			dark := img.ColorIndexAt(x, y) == 1
+			u, v := x-2, y-2
+			inside := u >= 0 && v >= 0 && u < len(bitmap)*3 && v < len(bitmap)*3
+			assert.Equal(t, dark, inside && bitmap[v/3][u/3])
+		}
+	}
+
+	q.WithModuleShape(ShapeCircle)
+	shaped, ok := q.ImageFit(size).(*image.NRGBA)
+	assert.True(t, ok)
+	assert.Equal(t, shaped.NRGBAAt(size-1, size-1), toNRGBA(q.BackgroundColor))
+}
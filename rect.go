@@ -0,0 +1,47 @@
+package qrcode
+
+type moduleRect struct {
+	x, y int
+	w, h int
+}
+
+// mergeDarkModules groups the dark modules of bitmap into rectangles.
+//
+// Horizontal runs of dark modules are merged first, then runs with the same
+// start and width on consecutive rows are merged into a single rectangle.
+func mergeDarkModules(bitmap [][]bool) []moduleRect {
+	var rects []moduleRect
+
+	type run struct{ x, w int }
+	open := map[run]int{}
+
+	for y, row := range bitmap {
+		next := map[run]int{}
+
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+
+			r := run{start, x - start}
+			if i, ok := open[r]; ok {
+				rects[i].h++
+				next[r] = i
+				continue
+			}
+
+			rects = append(rects, moduleRect{x: r.x, y: y, w: r.w, h: 1})
+			next[r] = len(rects) - 1
+		}
+
+		open = next
+	}
+
+	return rects
+}
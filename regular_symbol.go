@@ -1,6 +1,8 @@
 package qrcode
 
 import (
+	"image"
+
 	bitset "github.com/i9si-sistemas/bitset"
 )
 
@@ -120,12 +122,9 @@ func buildRegularSymbol(
 		size:   version.symbolSize(),
 	}
 
-	m.addFinderPatterns()
-	m.addAlignmentPatterns()
-	m.addTimingPatterns()
-	m.addFormatInfo()
-	m.addVersionInfo()
+	m.addFunctionPatterns()
 
+	m.symbol.pen = RoleData
 	ok, err := m.addData()
 	if !ok {
 		return nil, err
@@ -134,6 +133,21 @@ func buildRegularSymbol(
 	return m.symbol, nil
 }
 
+// addFunctionPatterns adds every module which is not part of the data,
+// recording its role.
+func (m *regularSymbol) addFunctionPatterns() {
+	m.symbol.pen = RoleFinder
+	m.addFinderPatterns()
+	m.symbol.pen = RoleAlignment
+	m.addAlignmentPatterns()
+	m.symbol.pen = RoleTiming
+	m.addTimingPatterns()
+	m.symbol.pen = RoleFormat
+	m.addFormatInfo()
+	m.symbol.pen = RoleVersion
+	m.addVersionInfo()
+}
+
 func (m *regularSymbol) addFinderPatterns() {
 	fpSize := finderPatternSize
 	fp := finderPattern
@@ -229,36 +243,70 @@ const (
 )
 
 func (m *regularSymbol) addData() (bool, error) {
+	m.forEachDataModule(m.data.Len(), func(i, x, y int) {
+		m.symbol.set(x, y, maskBit(m.mask, x, y) != m.data.At(i))
+	})
+
+	return black, nil
+}
+
+// maskBit reports whether mask pattern mask inverts the data module at x, y.
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (y+x)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (y+x)%3 == 0
+	case 4:
+		return (y/2+x/3)%2 == 0
+	case 5:
+		return (y*x)%2+(y*x)%3 == 0
+	case 6:
+		return ((y*x)%2+((y*x)%3))%2 == 0
+	case 7:
+		return ((y+x)%2+((y*x)%3))%2 == 0
+	}
+	return false
+}
+
+// dataModulePositions returns the position of every bit of the interleaved
+// codewords and remainder bits of a symbol of version v, in placement order.
+func dataModulePositions(v qrCodeVersion) []image.Point {
+	m := &regularSymbol{
+		version: v,
+		symbol:  newSymbol(v.symbolSize(), 0),
+		size:    v.symbolSize(),
+	}
+	m.addFunctionPatterns()
+
+	n := m.symbol.numEmptyModules()
+	positions := make([]image.Point, 0, n)
+	m.forEachDataModule(n, func(_, x, y int) {
+		m.symbol.set(x, y, white)
+		positions = append(positions, image.Pt(x, y))
+	})
+
+	return positions
+}
+
+// forEachDataModule calls fn with the position of the first n data modules,
+// in placement order. fn must set the module, as the next position is the
+// next empty module.
+func (m *regularSymbol) forEachDataModule(n int, fn func(i, x, y int)) {
 	xOffset := 1
 	dir := up
 
 	x := m.size - 2
 	y := m.size - 1
 
-	for i := range m.data.Len() {
-		var mask bool
-		switch m.mask {
-		case 0:
-			mask = (y+x+xOffset)%2 == 0
-		case 1:
-			mask = y%2 == 0
-		case 2:
-			mask = (x+xOffset)%3 == 0
-		case 3:
-			mask = (y+x+xOffset)%3 == 0
-		case 4:
-			mask = (y/2+(x+xOffset)/3)%2 == 0
-		case 5:
-			mask = (y*(x+xOffset))%2+(y*(x+xOffset))%3 == 0
-		case 6:
-			mask = ((y*(x+xOffset))%2+((y*(x+xOffset))%3))%2 == 0
-		case 7:
-			mask = ((y+x+xOffset)%2+((y*(x+xOffset))%3))%2 == 0
-		}
+	for i := range n {
+		fn(i, x+xOffset, y)
 
-		m.symbol.set(x+xOffset, y, mask != m.data.At(i))
-
-		if i == m.data.Len()-1 {
+		if i == n-1 {
 			break
 		}
 
@@ -294,6 +342,4 @@ func (m *regularSymbol) addData() (bool, error) {
 			}
 		}
 	}
-
-	return black, nil
 }
@@ -0,0 +1,241 @@
+package qrcode
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Renderer writes the modules of a QRCode in an output format.
+type Renderer interface {
+	Render(w io.Writer, m *ModuleMatrix) error
+}
+
+// RendererFunc is a function used as a Renderer.
+type RendererFunc func(w io.Writer, m *ModuleMatrix) error
+
+func (f RendererFunc) Render(w io.Writer, m *ModuleMatrix) error {
+	return f(w, m)
+}
+
+type namedRenderer struct {
+	name     string
+	renderer Renderer
+
+	// write, when set, writes the QRCode with its own settings instead of
+	// rendering its modules with renderer.
+	write func(q *QRCode, w io.Writer) error
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   []namedRenderer
+)
+
+func init() {
+	registerRenderer(namedRenderer{
+		name:     "png",
+		renderer: PNGRenderer{Size: DefaultFileSize},
+		write: func(q *QRCode, w io.Writer) error {
+			return q.Write(DefaultFileSize, w)
+		},
+	})
+	RegisterRenderer("text", TextRenderer{})
+	RegisterRenderer("small-text", TextRenderer{Small: true})
+	registerRenderer(namedRenderer{
+		name:     "tikz",
+		renderer: TikZRenderer{},
+		write: func(q *QRCode, w io.Writer) error {
+			return q.WriteTikZ(w, TikZOptions{})
+		},
+	})
+}
+
+// RegisterRenderer registers a renderer used by RenderNamed. Registering a
+// name again replaces the previous renderer.
+func RegisterRenderer(name string, r Renderer) {
+	registerRenderer(namedRenderer{name: strings.ToLower(name), renderer: r})
+}
+
+func registerRenderer(n namedRenderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+
+	i := slices.IndexFunc(renderers, func(other namedRenderer) bool { return other.name == n.name })
+	if i < 0 {
+		renderers = append(renderers, n)
+	} else {
+		renderers[i] = n
+	}
+}
+
+// LookupRenderer returns the renderer registered under name.
+func LookupRenderer(name string) (Renderer, bool) {
+	n, ok := lookupRenderer(name)
+	return n.renderer, ok
+}
+
+func lookupRenderer(name string) (namedRenderer, bool) {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	name = strings.ToLower(name)
+	i := slices.IndexFunc(renderers, func(n namedRenderer) bool { return n.name == name })
+	if i < 0 {
+		return namedRenderer{}, false
+	}
+	return renderers[i], true
+}
+
+// Renderers returns the names of the registered renderers.
+func Renderers() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	names := make([]string, len(renderers))
+	for i, n := range renderers {
+		names[i] = n.name
+	}
+	return names
+}
+
+// Render writes the modules of the QRCode, with the default quiet zone, with
+// r. Renderers draw the modules alone, so it returns ErrFrameUnsupported for
+// a framed QRCode.
+func (q *QRCode) Render(out io.Writer, r Renderer) error {
+	if err := q.checkColors(); err != nil {
+		return err
+	}
+	if err := q.checkUnframed("renderer"); err != nil {
+		return err
+	}
+	return r.Render(out, q.Matrix(0))
+}
+
+// RenderNamed writes the QRCode with the renderer registered under name.
+//
+// The built-in "png" and "tikz" renderers draw the QRCode with its own
+// colors and styling, as Write and WriteTikZ do, rather than the defaults of
+// PNGRenderer and TikZRenderer. The "png" image is DefaultFileSize pixels
+// wide. The text renderers draw the modules alone, as ToString does.
+func (q *QRCode) RenderNamed(out io.Writer, name string) error {
+	n, ok := lookupRenderer(name)
+	if !ok {
+		return fmt.Errorf("unknown renderer %q", name)
+	}
+	if n.write != nil {
+		return n.write(q, out)
+	}
+	return q.Render(out, n.renderer)
+}
+
+// PNGRenderer writes a PNG image of square modules of a single color.
+type PNGRenderer struct {
+	// Size is the width of the image in pixels, as for QRCode.Image.
+	Size int
+
+	// ForegroundColor and BackgroundColor default to black and white.
+	ForegroundColor color.Color
+	BackgroundColor color.Color
+
+	RasterMode RasterMode
+
+	// Compression is the PNG compression level. The zero value uses
+	// png.BestCompression.
+	Compression png.CompressionLevel
+}
+
+func (r PNGRenderer) Render(w io.Writer, m *ModuleMatrix) error {
+	foreground, background := r.ForegroundColor, r.BackgroundColor
+	if foreground == nil {
+		foreground = color.Black
+	}
+	if background == nil {
+		background = color.White
+	}
+
+	l := imageLayout(r.Size, m.size)
+	if r.RasterMode == RasterPaletted {
+		return writeBilevelPNG(w, m.dark, l, foreground, background, r.Compression)
+	}
+
+	encoder := png.Encoder{CompressionLevel: pngCompression(r.Compression)}
+	return encoder.Encode(w, plainImage(m.dark, l, r.RasterMode, foreground, background))
+}
+
+// pngRenderer returns the renderer of a PNG of the QRCode.
+func (q *QRCode) pngRenderer(size int) PNGRenderer {
+	return PNGRenderer{
+		Size:            size,
+		ForegroundColor: q.ForegroundColor,
+		BackgroundColor: q.BackgroundColor,
+		RasterMode:      q.RasterMode,
+		Compression:     q.PNGCompression,
+	}
+}
+
+// TextRenderer writes the modules as Unicode block characters, as
+// QRCode.ToString and QRCode.ToSmallString do.
+type TextRenderer struct {
+	// Inverse draws light modules instead of dark ones, for light text on
+	// a dark background.
+	Inverse bool
+
+	// Small draws two rows of modules per line of text.
+	Small bool
+}
+
+func (r TextRenderer) Render(w io.Writer, m *ModuleMatrix) error {
+	bits := m.dark
+	buf := bufio.NewWriter(w)
+
+	if !r.Small {
+		for y := range bits {
+			for x := range bits[y] {
+				if bits[y][x] != r.Inverse {
+					buf.WriteString("  ")
+				} else {
+					buf.WriteString("██")
+				}
+			}
+			buf.WriteString("\n")
+		}
+		return buf.Flush()
+	}
+
+	for y := 0; y < len(bits)-1; y += 2 {
+		for x := range bits[y] {
+			if bits[y][x] == bits[y+1][x] {
+				if bits[y][x] != r.Inverse {
+					buf.WriteString(" ")
+				} else {
+					buf.WriteString("█")
+				}
+			} else {
+				if bits[y][x] != r.Inverse {
+					buf.WriteString("▄")
+				} else {
+					buf.WriteString("▀")
+				}
+			}
+		}
+		buf.WriteString("\n")
+	}
+	if len(bits)%2 == 1 {
+		y := len(bits) - 1
+		for x := range bits[y] {
+			if bits[y][x] != r.Inverse {
+				buf.WriteString(" ")
+			} else {
+				buf.WriteString("▀")
+			}
+		}
+		buf.WriteString("\n")
+	}
+	return buf.Flush()
+}
@@ -0,0 +1,102 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestModuleMatrix(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	m := q.Matrix(2)
+	bitmap := q.Bitmap()
+	assert.Equal(t, m.Size(), len(bitmap)-4)
+	assert.Equal(t, m.QuietZone(), 2)
+
+	assert.True(t, m.Dark(2, 2))
+	assert.False(t, m.Dark(-1, 0))
+	assert.Equal(t, m.Role(0, 0), RoleQuietZone)
+	assert.Equal(t, m.Role(2, 2), RoleFinder)
+	assert.Equal(t, m.Role(m.Size()-3, m.Size()-3), RoleData)
+	assert.Equal(t, m.Role(m.Size(), 0), RoleQuietZone)
+}
+
+func TestRenderNamed(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.RenderNamed(&buf, "TEXT"))
+	assert.Equal(t, buf.String(), q.ToString(false))
+
+	buf.Reset()
+	assert.NoError(t, q.RenderNamed(&buf, "png"))
+	img, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dx(), DefaultFileSize)
+
+	assert.Error(t, q.RenderNamed(&buf, "missing"))
+}
+
+func TestRenderNamedStyling(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithColors(navy, color.White).WithModuleShape(ShapeCircle)
+	assert.NoError(t, q.WithFrame(FrameOptions{Caption: "i9si"}))
+
+	// The built-in renderers draw the QRCode as Write and WriteTikZ do.
+	var buf bytes.Buffer
+	assert.NoError(t, q.RenderNamed(&buf, "png"))
+	assert.Equal(t, buf.Bytes(), q.PNG(DefaultFileSize))
+
+	var tikz bytes.Buffer
+	buf.Reset()
+	assert.NoError(t, q.RenderNamed(&buf, "tikz"))
+	assert.NoError(t, q.WriteTikZ(&tikz, TikZOptions{}))
+	assert.Equal(t, buf.String(), tikz.String())
+
+	// A renderer registered under a built-in name renders the modules.
+	builtin, _ := lookupRenderer("png")
+	t.Cleanup(func() { registerRenderer(builtin) })
+	RegisterRenderer("png", PNGRenderer{Size: 100})
+
+	q.WithoutFrame()
+	buf.Reset()
+	assert.NoError(t, q.RenderNamed(&buf, "png"))
+	img, err := png.Decode(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dx(), 100)
+	assert.Equal(t, toNRGBA(img.At(0, 0)), toNRGBA(color.White))
+}
+
+func TestRegisterRenderer(t *testing.T) {
+	RegisterRenderer("count", RendererFunc(func(w io.Writer, m *ModuleMatrix) error {
+		dark := 0
+		for y := range m.Size() {
+			for x := range m.Size() {
+				if m.Dark(x, y) {
+					dark++
+				}
+			}
+		}
+		_, err := fmt.Fprint(w, dark)
+		return err
+	}))
+	assert.True(t, slices.Contains(Renderers(), "count"))
+
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.RenderNamed(&buf, "count"))
+	assert.Equal(t, buf.String(), fmt.Sprint(strings.Count(q.ToString(true), "██")))
+}
@@ -0,0 +1,198 @@
+package qrcode
+
+import (
+	"math"
+	"slices"
+)
+
+// ModuleShape is the shape used to draw the dark data modules of a QRCode.
+//
+// Finder, alignment and timing patterns are always drawn as squares so the
+// symbol stays detectable.
+type ModuleShape int
+
+const (
+	ShapeSquare ModuleShape = iota
+	ShapeRoundedSquare
+	ShapeCircle
+	ShapeDiamond
+	// ShapeLiquid rounds the corners of dark modules that are not
+	// connected to a neighbour, so adjacent modules merge into blobs.
+	ShapeLiquid
+)
+
+const roundedSquareRadius = 0.3
+
+// kappa is the distance of the control points of a cubic Bézier curve
+// approximating a quarter circle of radius 1.
+const kappa = 0.5522847498
+
+// outline is a closed shape in module coordinates.
+type outline interface {
+	contains(x, y float64) bool
+	path(p pathWriter)
+}
+
+type point struct {
+	x, y float64
+}
+
+// roundedRect is a rectangle with corner radii r, clockwise from the top-left
+// corner.
+type roundedRect struct {
+	x, y, w, h float64
+	r          [4]float64
+}
+
+func (rr roundedRect) contains(x, y float64) bool {
+	if x < rr.x || y < rr.y || x > rr.x+rr.w || y > rr.y+rr.h {
+		return false
+	}
+
+	corners := [4]point{
+		{rr.x + rr.r[0], rr.y + rr.r[0]},
+		{rr.x + rr.w - rr.r[1], rr.y + rr.r[1]},
+		{rr.x + rr.w - rr.r[2], rr.y + rr.h - rr.r[2]},
+		{rr.x + rr.r[3], rr.y + rr.h - rr.r[3]},
+	}
+
+	for i, c := range corners {
+		r := rr.r[i]
+		if r == 0 {
+			continue
+		}
+
+		left := i == 0 || i == 3
+		top := i == 0 || i == 1
+		if (left && x >= c.x) || (!left && x <= c.x) || (top && y >= c.y) || (!top && y <= c.y) {
+			continue
+		}
+
+		return math.Hypot(x-c.x, y-c.y) <= r
+	}
+
+	return true
+}
+
+func (rr roundedRect) path(p pathWriter) {
+	x, y, w, h, r := rr.x, rr.y, rr.w, rr.h, rr.r
+
+	p.moveTo(x+r[0], y)
+	p.lineTo(x+w-r[1], y)
+	if r[1] > 0 {
+		p.curveTo(x+w-r[1]+kappa*r[1], y, x+w, y+r[1]-kappa*r[1], x+w, y+r[1])
+	}
+	p.lineTo(x+w, y+h-r[2])
+	if r[2] > 0 {
+		p.curveTo(x+w, y+h-r[2]+kappa*r[2], x+w-r[2]+kappa*r[2], y+h, x+w-r[2], y+h)
+	}
+	p.lineTo(x+r[3], y+h)
+	if r[3] > 0 {
+		p.curveTo(x+r[3]-kappa*r[3], y+h, x, y+h-r[3]+kappa*r[3], x, y+h-r[3])
+	}
+	p.lineTo(x, y+r[0])
+	if r[0] > 0 {
+		p.curveTo(x, y+r[0]-kappa*r[0], x+r[0]-kappa*r[0], y, x+r[0], y)
+	}
+	p.closePath()
+}
+
+// polygon is a closed polygon, with its points in clockwise order.
+type polygon []point
+
+func (pg polygon) contains(x, y float64) bool {
+	inside := false
+	for i, j := 0, len(pg)-1; i < len(pg); j, i = i, i+1 {
+		a, b := pg[i], pg[j]
+		if (a.y > y) != (b.y > y) && x < (b.x-a.x)*(y-a.y)/(b.y-a.y)+a.x {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (pg polygon) path(p pathWriter) {
+	for i, pt := range pg {
+		if i == 0 {
+			p.moveTo(pt.x, pt.y)
+		} else {
+			p.lineTo(pt.x, pt.y)
+		}
+	}
+	p.closePath()
+}
+
+// moduleOutline returns the outline of the dark module at x, y.
+func (m *ModuleMatrix) moduleOutline(shape ModuleShape, x, y int) outline {
+	fx, fy := float64(x), float64(y)
+
+	switch shape {
+	case ShapeRoundedSquare:
+		r := roundedSquareRadius
+		return roundedRect{fx, fy, 1, 1, [4]float64{r, r, r, r}}
+	case ShapeCircle:
+		return roundedRect{fx, fy, 1, 1, [4]float64{0.5, 0.5, 0.5, 0.5}}
+	case ShapeDiamond:
+		return polygon{{fx + 0.5, fy}, {fx + 1, fy + 0.5}, {fx + 0.5, fy + 1}, {fx, fy + 0.5}}
+	case ShapeLiquid:
+		var r [4]float64
+		corners := [4][2]int{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+		for i, c := range corners {
+			if !m.get(x+c[0], y) && !m.get(x, y+c[1]) {
+				r[i] = 0.5
+			}
+		}
+		return roundedRect{fx, fy, 1, 1, r}
+	default:
+		return roundedRect{fx, fy, 1, 1, [4]float64{}}
+	}
+}
+
+// isSquare reports whether the module at x, y is drawn as a plain square.
+func (m *ModuleMatrix) isSquare(shape ModuleShape, x, y int) bool {
+	return shape == ShapeSquare || m.role[y][x].isFunctionPattern()
+}
+
+// covers reports whether the point u, v within the module at x, y is dark.
+func (m *ModuleMatrix) covers(shape ModuleShape, x, y int, u, v float64) bool {
+	if !m.get(x, y) {
+		return false
+	}
+	if m.isSquare(shape, x, y) {
+		return true
+	}
+	return m.moduleOutline(shape, x, y).contains(float64(x)+u, float64(y)+v)
+}
+
+// writePaths writes the outlines of the dark modules with one of the given
+// roles to p. Modules drawn as squares are merged into rectangles.
+func (m *ModuleMatrix) writePaths(p pathWriter, shape ModuleShape, roles []ModuleRole) {
+	drawn := make([][]bool, m.size)
+	squares := make([][]bool, m.size)
+	for y := range squares {
+		drawn[y] = make([]bool, m.size)
+		squares[y] = make([]bool, m.size)
+		for x := range squares[y] {
+			drawn[y][x] = m.dark[y][x] && slices.Contains(roles, m.role[y][x])
+			squares[y][x] = drawn[y][x] && m.isSquare(shape, x, y)
+		}
+	}
+
+	for _, r := range mergeDarkModules(squares) {
+		p.rect(r.x, r.y, r.w, r.h)
+	}
+
+	for y := range m.size {
+		for x := range m.size {
+			if drawn[y][x] && !squares[y][x] {
+				m.moduleOutline(shape, x, y).path(p)
+			}
+		}
+	}
+}
+
+// WithModuleShape sets the shape of the dark data modules of the QRCode.
+func (q *QRCode) WithModuleShape(shape ModuleShape) *QRCode {
+	q.ModuleShape = shape
+	return q
+}
@@ -0,0 +1,86 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestModuleRoles(t *testing.T) {
+	q, err := NewWithForcedVersion(i9siDomain, 7, Medium)
+	assert.NoError(t, err)
+
+	m := q.Matrix(NoQuietZone)
+	size := m.size
+
+	assert.Equal(t, m.role[0][0], RoleFinder)
+	assert.Equal(t, m.role[6][size-1], RoleFinder)
+	assert.Equal(t, m.role[size-1][0], RoleFinder)
+	assert.Equal(t, m.role[6][10], RoleTiming)
+	assert.Equal(t, m.role[22][22], RoleAlignment)
+	assert.Equal(t, m.role[8][0], RoleFormat)
+	assert.Equal(t, m.role[0][size-9], RoleVersion)
+	assert.Equal(t, m.role[size-1][size-1], RoleData)
+
+	m = q.Matrix(0)
+	assert.Equal(t, m.role[0][0], RoleQuietZone)
+	assert.Equal(t, m.role[4][4], RoleFinder)
+}
+
+func TestOutlineContains(t *testing.T) {
+	circle := roundedRect{0, 0, 1, 1, [4]float64{0.5, 0.5, 0.5, 0.5}}
+	assert.True(t, circle.contains(0.5, 0.5))
+	assert.True(t, circle.contains(0.5, 0.01))
+	assert.False(t, circle.contains(0.05, 0.05))
+	assert.False(t, circle.contains(0.95, 0.95))
+
+	leaf := roundedRect{0, 0, 1, 1, [4]float64{0.5, 0, 0.5, 0}}
+	assert.False(t, leaf.contains(0.05, 0.05))
+	assert.True(t, leaf.contains(0.95, 0.05))
+
+	diamond := polygon{{0.5, 0}, {1, 0.5}, {0.5, 1}, {0, 0.5}}
+	assert.True(t, diamond.contains(0.5, 0.5))
+	assert.False(t, diamond.contains(0.1, 0.1))
+}
+
+func TestModuleShapeSVG(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	square := string(q.SVG(SVGOptions{}))
+	circle := string(q.WithModuleShape(ShapeCircle).SVG(SVGOptions{}))
+
+	assert.False(t, strings.Contains(square, "C"))
+	assert.True(t, strings.Contains(circle, "C"))
+	assert.False(t, strings.Contains(circle, "crispEdges"))
+	assert.True(t, strings.Contains(circle, "M4 4h7v1h-7z"))
+}
+
+func TestModuleShapeImage(t *testing.T) {
+	for _, shape := range []ModuleShape{ShapeRoundedSquare, ShapeCircle, ShapeDiamond, ShapeLiquid} {
+		q, err := New(i9siDomain, Medium)
+		assert.NoError(t, err)
+
+		img, ok := q.WithModuleShape(shape).Image(-8).(*image.NRGBA)
+		assert.True(t, ok)
+
+		partial := false
+		for _, v := range img.Pix {
+			if v != 0 && v != 0xff {
+				partial = true
+				break
+			}
+		}
+		assert.True(t, partial, "shaped modules are anti-aliased")
+
+		finder := img.NRGBAAt(4*8+3, 4*8+3)
+		assert.Equal(t, finder.R, uint8(0))
+
+		buf := new(bytes.Buffer)
+		assert.NoError(t, q.WriteEPS(buf, EPSOptions{}))
+		assert.True(t, strings.Contains(buf.String(), " lineto\n"))
+	}
+}
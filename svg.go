@@ -0,0 +1,252 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// SVGOptions configures the SVG output of a QRCode.
+type SVGOptions struct {
+	// Width and Height set the physical size of the document, e.g. "40mm"
+	// or "2in". When empty the size defaults to the viewBox in module
+	// units. If only Width is set, Height uses the same value, or follows
+	// the aspect ratio of a framed QRCode.
+	Width  string
+	Height string
+
+	// Title and Description add <title> and <desc> elements for
+	// accessibility.
+	Title       string
+	Description string
+
+	// IDPrefix starts the ids of the elements of the document, such as the
+	// title and the gradient. Empty uses DefaultSVGIDPrefix. Give every
+	// QR Code inlined in the same HTML page its own prefix, as ids must be
+	// unique within a page.
+	IDPrefix string
+}
+
+// DefaultSVGIDPrefix is the default prefix of the ids in SVG output.
+const DefaultSVGIDPrefix = "qrcode-"
+
+// SVG returns an SVG image of the QRCode.
+func (q *QRCode) SVG(opts SVGOptions) []byte {
+	buf := new(bytes.Buffer)
+	q.writeSVG(buf, opts)
+	return buf.Bytes()
+}
+
+// WriteSVG writes an SVG image of the QRCode.
+func (q *QRCode) WriteSVG(out io.Writer, opts SVGOptions) error {
+	if err := q.checkColors(); err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	q.writeSVG(buf, opts)
+	if _, err := out.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q *QRCode) writeSVG(buf *bytes.Buffer, opts SVGOptions) {
+	m := q.Matrix(0)
+
+	prefix := opts.IDPrefix
+	if prefix == "" {
+		prefix = DefaultSVGIDPrefix
+	}
+	prefix = svgEscape(prefix)
+
+	frame := q.canvas(m)
+	viewBox := []string{formatNumber(frame.x), formatNumber(frame.y), formatNumber(frame.w), formatNumber(frame.h)}
+
+	// A framed QRCode is not square, so a missing height is left to the
+	// aspect ratio of the viewBox.
+	width, height := opts.Width, opts.Height
+	switch {
+	case width == "":
+		width = viewBox[2]
+		if height == "" {
+			height = viewBox[3]
+		}
+	case height == "" && q.frame == nil:
+		height = width
+	}
+
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%s"`, svgEscape(width))
+	if height != "" {
+		fmt.Fprintf(buf, ` height="%s"`, svgEscape(height))
+	}
+	fmt.Fprintf(buf, ` viewBox="%s"`, strings.Join(viewBox, " "))
+	if q.isPlain() {
+		buf.WriteString(` shape-rendering="crispEdges"`)
+	}
+
+	if opts.Title != "" || opts.Description != "" {
+		var labels []string
+		if opts.Title != "" {
+			labels = append(labels, prefix+"title")
+		}
+		if opts.Description != "" {
+			labels = append(labels, prefix+"desc")
+		}
+		fmt.Fprintf(buf, ` role="img" aria-labelledby="%s"`, strings.Join(labels, " "))
+	}
+	buf.WriteString(">\n")
+
+	if opts.Title != "" {
+		fmt.Fprintf(buf, "<title id=\"%stitle\">%s</title>\n", prefix, svgEscape(opts.Title))
+	}
+	if opts.Description != "" {
+		fmt.Fprintf(buf, "<desc id=\"%sdesc\">%s</desc>\n", prefix, svgEscape(opts.Description))
+	}
+
+	if fill := svgFill(q.BackgroundColor); fill != "" {
+		if q.frame != nil {
+			fmt.Fprintf(buf, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\"%s/>\n",
+				viewBox[0], viewBox[1], viewBox[2], viewBox[3], fill)
+		} else {
+			fmt.Fprintf(buf, "<rect width=\"%d\" height=\"%d\"%s/>\n", m.size, m.size, fill)
+		}
+	}
+
+	v := svgPainter{buf: buf, gradientID: prefix + "gradient"}
+	if f := q.foregroundFill(m); f.gradient != nil {
+		buf.WriteString("<defs>\n")
+		writeSVGGradient(buf, f, v.gradientID)
+		buf.WriteString("</defs>\n")
+	}
+
+	q.drawVector(v, m)
+
+	if q.logo != nil {
+		writeSVGLogo(buf, q.logo, m)
+	}
+
+	paintFrame(v, frame)
+
+	buf.WriteString("</svg>\n")
+}
+
+func writeSVGGradient(buf *bytes.Buffer, f fill, id string) {
+	switch f.gradient.Kind {
+	case RadialGradient:
+		cx, cy, r := f.radialCoords()
+		fmt.Fprintf(buf, `<radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="%s" cy="%s" r="%s">`+"\n",
+			id, formatNumber(cx), formatNumber(cy), formatNumber(r))
+	default:
+		x0, y0, x1, y1 := f.linearCoords()
+		fmt.Fprintf(buf, `<linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%s" y1="%s" x2="%s" y2="%s">`+"\n",
+			id, formatNumber(x0), formatNumber(y0), formatNumber(x1), formatNumber(y1))
+	}
+
+	for _, stop := range f.stops {
+		n := toNRGBA(stop.Color)
+		fmt.Fprintf(buf, `<stop offset="%s" stop-color="#%02x%02x%02x"`,
+			formatNumber(stop.Offset), n.R, n.G, n.B)
+		if n.A != 0xff {
+			fmt.Fprintf(buf, ` stop-opacity="%.3g"`, float64(n.A)/0xff)
+		}
+		buf.WriteString("/>\n")
+	}
+
+	if f.gradient.Kind == RadialGradient {
+		buf.WriteString("</radialGradient>\n")
+	} else {
+		buf.WriteString("</linearGradient>\n")
+	}
+}
+
+// writeSVGLogo writes the logo as an embedded PNG image.
+func writeSVGLogo(buf *bytes.Buffer, l *logo, m *ModuleMatrix) {
+	img := new(bytes.Buffer)
+	if err := png.Encode(img, l.image); err != nil {
+		return
+	}
+
+	_, x, y, w, h := l.bounds(m)
+	fmt.Fprintf(buf, `<image x="%s" y="%s" width="%s" height="%s" preserveAspectRatio="none" href="data:image/png;base64,%s"/>`+"\n",
+		formatNumber(x), formatNumber(y), formatNumber(w), formatNumber(h),
+		base64.StdEncoding.EncodeToString(img.Bytes()))
+}
+
+type svgPainter struct {
+	buf        *bytes.Buffer
+	gradientID string
+}
+
+func (v svgPainter) paint(f fill, evenOdd bool, draw func(p pathWriter)) {
+	attrs := svgFill(f.color)
+	if f.gradient != nil {
+		attrs = fmt.Sprintf(` fill="url(#%s)"`, v.gradientID)
+	}
+	if attrs == "" {
+		return
+	}
+
+	v.buf.WriteString("<path" + attrs)
+	if evenOdd {
+		v.buf.WriteString(` fill-rule="evenodd"`)
+	}
+	v.buf.WriteString(` d="`)
+	draw(svgPath{v.buf})
+	v.buf.WriteString("\"/>\n")
+}
+
+type svgPath struct {
+	buf *bytes.Buffer
+}
+
+func (p svgPath) moveTo(x, y float64) {
+	fmt.Fprintf(p.buf, "M%s %s", formatNumber(x), formatNumber(y))
+}
+
+func (p svgPath) lineTo(x, y float64) {
+	fmt.Fprintf(p.buf, "L%s %s", formatNumber(x), formatNumber(y))
+}
+
+func (p svgPath) curveTo(x1, y1, x2, y2, x, y float64) {
+	fmt.Fprintf(p.buf, "C%s %s %s %s %s %s",
+		formatNumber(x1), formatNumber(y1),
+		formatNumber(x2), formatNumber(y2),
+		formatNumber(x), formatNumber(y))
+}
+
+func (p svgPath) closePath() {
+	p.buf.WriteString("z")
+}
+
+func (p svgPath) rect(x, y, w, h int) {
+	fmt.Fprintf(p.buf, "M%d %dh%dv%dh-%dz", x, y, w, h, w)
+}
+
+// svgFill returns the fill attributes for c, or an empty string when c is
+// fully transparent.
+func svgFill(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if n.A == 0 {
+		return ""
+	}
+
+	fill := fmt.Sprintf(` fill="#%02x%02x%02x"`, n.R, n.G, n.B)
+	if n.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.3g"`, float64(n.A)/0xff)
+	}
+
+	return fill
+}
+
+func svgEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestMergeDarkModules(t *testing.T) {
+	bitmap := [][]bool{
+		{black, black, white, black},
+		{black, black, white, black},
+		{white, black, white, white},
+	}
+
+	rects := mergeDarkModules(bitmap)
+	assert.Equal(t, len(rects), 3)
+
+	dark := 0
+	for _, r := range rects {
+		dark += r.w * r.h
+	}
+	assert.Equal(t, dark, 7)
+}
+
+func TestSVG(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	svg := string(q.SVG(SVGOptions{
+		Width:       "40mm",
+		Title:       "Website",
+		Description: "Link to <i9si>",
+	}))
+
+	size := len(q.Bitmap())
+	assert.True(t, strings.Contains(svg, `width="40mm" height="40mm"`))
+	assert.True(t, strings.Contains(svg, `viewBox="0 0 `+strconv.Itoa(size)+` `+strconv.Itoa(size)+`"`))
+	assert.True(t, strings.Contains(svg, "<title id=\"qrcode-title\">Website</title>"))
+	assert.True(t, strings.Contains(svg, "&lt;i9si&gt;"))
+	assert.Equal(t, strings.Count(svg, "<path"), 1)
+
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			assert.Equal(t, err.Error(), "EOF")
+			break
+		}
+	}
+}
+
+func TestSVGTransparentBackground(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	q.WithColors(color.RGBA{0x11, 0x22, 0x33, 0xff}, color.Transparent)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, q.WriteSVG(buf, SVGOptions{}))
+
+	svg := buf.String()
+	assert.False(t, strings.Contains(svg, "<rect"))
+	assert.True(t, strings.Contains(svg, `fill="#112233"`))
+}
@@ -3,9 +3,13 @@ package qrcode
 type symbol struct {
 	module        [][]bool
 	isUsed        [][]bool
+	role          [][]ModuleRole
 	size          int
 	symbolSize    int
 	quietZoneSize int
+
+	// pen is the role recorded for modules as they are first set.
+	pen ModuleRole
 }
 
 func newSymbol(size int, quietZoneSize int) *symbol {
@@ -13,10 +17,12 @@ func newSymbol(size int, quietZoneSize int) *symbol {
 
 	m.module = make([][]bool, size+2*quietZoneSize)
 	m.isUsed = make([][]bool, size+2*quietZoneSize)
+	m.role = make([][]ModuleRole, size+2*quietZoneSize)
 
 	for i := range m.module {
 		m.module[i] = make([]bool, size+2*quietZoneSize)
 		m.isUsed[i] = make([]bool, size+2*quietZoneSize)
+		m.role[i] = make([]ModuleRole, size+2*quietZoneSize)
 	}
 
 	m.size = size + 2*quietZoneSize
@@ -51,10 +57,18 @@ func (m *symbol) numEmptyModules() int {
 
 func (m *symbol) set(x int, y int, v bool) {
 	index := func(v int) int { return v + m.quietZoneSize }
+	if !m.isUsed[index(y)][index(x)] {
+		m.role[index(y)][index(x)] = m.pen
+	}
 	m.module[index(y)][index(x)] = v
 	m.isUsed[index(y)][index(x)] = true
 }
 
+func (m *symbol) roleAt(x int, y int) ModuleRole {
+	index := func(v int) int { return v + m.quietZoneSize }
+	return m.role[index(y)][index(x)]
+}
+
 func (m *symbol) values(x, y int) (module, isUsed bool) {
 	index := func(v int) int { return v + m.quietZoneSize }
 	return m.module[index(y)][index(x)], m.isUsed[index(y)][index(x)]
@@ -78,6 +92,25 @@ func (m *symbol) bitmap() [][]bool {
 	return module
 }
 
+// bitmapWithQuietZone returns the symbol surrounded by a quiet zone of
+// quietZoneSize modules, regardless of the quiet zone it was built with.
+func (m *symbol) bitmapWithQuietZone(quietZoneSize int) [][]bool {
+	size := m.symbolSize + 2*quietZoneSize
+	module := make([][]bool, size)
+
+	for i := range module {
+		module[i] = make([]bool, size)
+	}
+
+	for y := range m.symbolSize {
+		for x := range m.symbolSize {
+			module[y+quietZoneSize][x+quietZoneSize] = m.get(x, y)
+		}
+	}
+
+	return module
+}
+
 func (m *symbol) String() string {
 	var result string
 
@@ -0,0 +1,227 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"strings"
+)
+
+// TerminalProtocol selects how a QRCode is drawn on a terminal.
+type TerminalProtocol int
+
+const (
+	// TerminalAuto detects the protocol from the environment.
+	TerminalAuto TerminalProtocol = iota
+	// TerminalText draws black and white block characters.
+	TerminalText
+	// TerminalANSI draws half block characters with ANSI 24-bit colors.
+	TerminalANSI
+	// TerminalSixel draws a Sixel image.
+	TerminalSixel
+	// TerminalKitty draws a PNG image with the Kitty graphics protocol.
+	TerminalKitty
+)
+
+// DefaultTerminalModuleSize is the default size of a module in pixels for
+// the Sixel and Kitty protocols.
+const DefaultTerminalModuleSize = 4
+
+// TerminalOptions configures the terminal output of a QRCode.
+type TerminalOptions struct {
+	// Protocol overrides the detected terminal protocol.
+	Protocol TerminalProtocol
+
+	// ModuleSize is the size of a module in pixels for the Sixel and
+	// Kitty protocols. Defaults to DefaultTerminalModuleSize.
+	ModuleSize int
+}
+
+// DetectTerminalProtocol returns the best protocol supported by the terminal,
+// based on the TERM, TERM_PROGRAM, COLORTERM and KITTY_WINDOW_ID environment
+// variables.
+func DetectTerminalProtocol() TerminalProtocol {
+	return detectTerminalProtocol(os.Getenv)
+}
+
+func detectTerminalProtocol(getenv func(string) string) TerminalProtocol {
+	term := getenv("TERM")
+
+	switch {
+	case term == "xterm-kitty", getenv("KITTY_WINDOW_ID") != "":
+		return TerminalKitty
+	case getenv("TERM_PROGRAM") == "WezTerm", getenv("TERM_PROGRAM") == "ghostty":
+		return TerminalKitty
+	case strings.Contains(term, "sixel"), term == "mlterm", strings.HasPrefix(term, "foot"):
+		return TerminalSixel
+	}
+
+	switch strings.ToLower(getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return TerminalANSI
+	}
+
+	return TerminalText
+}
+
+// WriteTerminal writes the QRCode for display on a terminal.
+func (q *QRCode) WriteTerminal(out io.Writer, opts TerminalOptions) error {
+	if opts.Protocol == TerminalAuto {
+		opts.Protocol = DetectTerminalProtocol()
+	}
+	if opts.ModuleSize <= 0 {
+		opts.ModuleSize = DefaultTerminalModuleSize
+	}
+	if err := q.checkColors(); err != nil {
+		return err
+	}
+	if opts.Protocol != TerminalKitty {
+		if err := q.checkUnframed("terminal text"); err != nil {
+			return err
+		}
+	}
+
+	var s string
+	switch opts.Protocol {
+	case TerminalText:
+		s = q.ToSmallString(false)
+	case TerminalANSI:
+		s = q.ansiString()
+	case TerminalSixel:
+		s = q.sixelString(opts.ModuleSize)
+	case TerminalKitty:
+		s = q.kittyString(opts.ModuleSize)
+	default:
+		return fmt.Errorf("invalid terminal protocol %d", opts.Protocol)
+	}
+
+	if _, err := io.WriteString(out, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ansiString draws two rows of modules per line using upper half blocks, with
+// the upper module as the foreground and the lower module as the background
+// color. Transparent colors are drawn as they appear on a white page.
+func (q *QRCode) ansiString() string {
+	bits := q.Bitmap()
+	foreground, background := q.pageColors()
+	colors := [2]color.NRGBA{background, foreground}
+	index := func(v bool) int {
+		if v {
+			return 1
+		}
+		return 0
+	}
+
+	var buf bytes.Buffer
+	for y := 0; y < len(bits); y += 2 {
+		last := [2]int{-1, -1}
+		for x := range bits[y] {
+			top := index(bits[y][x])
+			bottom := 0
+			if y+1 < len(bits) {
+				bottom = index(bits[y+1][x])
+			}
+
+			if last != [2]int{top, bottom} {
+				fg, bg := colors[top], colors[bottom]
+				fmt.Fprintf(&buf, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm",
+					fg.R, fg.G, fg.B, bg.R, bg.G, bg.B)
+				last = [2]int{top, bottom}
+			}
+			buf.WriteString("▀")
+		}
+		buf.WriteString("\x1b[0m\n")
+	}
+	return buf.String()
+}
+
+// sixelString draws the QRCode as a two color Sixel image, with the colors as
+// they appear on a white page.
+func (q *QRCode) sixelString(moduleSize int) string {
+	bits := q.Bitmap()
+	size := len(bits) * moduleSize
+
+	var buf bytes.Buffer
+	buf.WriteString("\x1bP0;1;0q")
+	fmt.Fprintf(&buf, "\"1;1;%d;%d", size, size)
+
+	foreground, background := q.pageColors()
+	for i, n := range []color.NRGBA{background, foreground} {
+		fmt.Fprintf(&buf, "#%d;2;%d;%d;%d", i,
+			int(n.R)*100/0xff, int(n.G)*100/0xff, int(n.B)*100/0xff)
+	}
+
+	row := make([]byte, size)
+	for band := 0; band < size; band += 6 {
+		for i, dark := range []bool{false, true} {
+			for x := range size {
+				var sixel byte
+				for bit := range 6 {
+					y := band + bit
+					if y < size && bits[y/moduleSize][x/moduleSize] == dark {
+						sixel |= 1 << bit
+					}
+				}
+				row[x] = '?' + sixel
+			}
+
+			fmt.Fprintf(&buf, "#%d", i)
+			writeSixelRow(&buf, row)
+			buf.WriteByte('$')
+		}
+		buf.WriteByte('-')
+	}
+
+	buf.WriteString("\x1b\\\n")
+	return buf.String()
+}
+
+// writeSixelRow writes a row of sixels using run-length encoding.
+func writeSixelRow(buf *bytes.Buffer, row []byte) {
+	for x := 0; x < len(row); {
+		n := 1
+		for x+n < len(row) && row[x+n] == row[x] {
+			n++
+		}
+
+		if n > 3 {
+			fmt.Fprintf(buf, "!%d%c", n, row[x])
+		} else {
+			buf.Write(bytes.Repeat(row[x:x+1], n))
+		}
+		x += n
+	}
+}
+
+// kittyString draws the QRCode as a PNG image using the Kitty graphics
+// protocol.
+func (q *QRCode) kittyString(moduleSize int) string {
+	const chunkSize = 4096
+
+	payload := base64.StdEncoding.EncodeToString(q.PNG(-moduleSize))
+
+	var buf bytes.Buffer
+	for i := 0; i < len(payload); i += chunkSize {
+		end := min(i+chunkSize, len(payload))
+
+		more := 1
+		if end == len(payload) {
+			more = 0
+		}
+
+		if i == 0 {
+			fmt.Fprintf(&buf, "\x1b_Ga=T,f=100,m=%d;%s\x1b\\", more, payload[i:end])
+		} else {
+			fmt.Fprintf(&buf, "\x1b_Gm=%d;%s\x1b\\", more, payload[i:end])
+		}
+	}
+
+	buf.WriteString("\n")
+	return buf.String()
+}
@@ -0,0 +1,96 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestDetectTerminalProtocol(t *testing.T) {
+	tests := []struct {
+		env      map[string]string
+		expected TerminalProtocol
+	}{
+		{map[string]string{"TERM": "xterm-kitty"}, TerminalKitty},
+		{map[string]string{"TERM": "xterm-256color", "KITTY_WINDOW_ID": "1"}, TerminalKitty},
+		{map[string]string{"TERM": "mlterm"}, TerminalSixel},
+		{map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, TerminalANSI},
+		{map[string]string{"TERM": "xterm-256color"}, TerminalText},
+		{map[string]string{}, TerminalText},
+	}
+
+	for _, test := range tests {
+		getenv := func(key string) string { return test.env[key] }
+		assert.Equal(t, detectTerminalProtocol(getenv), test.expected)
+	}
+}
+
+func TestWriteTerminalANSI(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithColors(color.RGBA{0x12, 0x34, 0x56, 0xff}, color.White)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, q.WriteTerminal(buf, TerminalOptions{Protocol: TerminalANSI}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, len(lines), (len(q.Bitmap())+1)/2)
+	assert.True(t, strings.Contains(buf.String(), "38;2;18;52;86"))
+}
+
+func TestWriteTerminalSixel(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, q.WriteTerminal(buf, TerminalOptions{Protocol: TerminalSixel, ModuleSize: 2}))
+
+	sixel := buf.String()
+	size := len(q.Bitmap()) * 2
+	assert.True(t, strings.HasPrefix(sixel, "\x1bP0;1;0q"))
+	assert.True(t, strings.HasSuffix(sixel, "\x1b\\\n"))
+	assert.Equal(t, strings.Count(sixel, "-"), (size+5)/6)
+}
+
+func TestWriteTerminalTransparentBackground(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithColors(color.Black, color.Transparent)
+
+	// A transparent background is drawn white, not black.
+	buf := new(bytes.Buffer)
+	assert.NoError(t, q.WriteTerminal(buf, TerminalOptions{Protocol: TerminalANSI}))
+	assert.True(t, strings.HasPrefix(buf.String(), "\x1b[38;2;255;255;255;48;2;255;255;255m"))
+
+	buf.Reset()
+	assert.NoError(t, q.WriteTerminal(buf, TerminalOptions{Protocol: TerminalSixel}))
+	assert.True(t, strings.Contains(buf.String(), "#0;2;100;100;100"))
+	assert.True(t, strings.Contains(buf.String(), "#1;2;0;0;0"))
+}
+
+func TestWriteTerminalKitty(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, q.WriteTerminal(buf, TerminalOptions{Protocol: TerminalKitty, ModuleSize: 3}))
+
+	var payload strings.Builder
+	for _, chunk := range strings.Split(buf.String(), "\x1b\\") {
+		if i := strings.Index(chunk, ";"); i >= 0 {
+			payload.WriteString(chunk[i+1:])
+		}
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload.String())
+	assert.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, img.Bounds().Dx(), len(q.Bitmap())*3)
+}
@@ -0,0 +1,127 @@
+package qrcode
+
+import (
+	"math"
+	"strings"
+)
+
+// DefaultCellAspect is the height to width ratio of a character cell in
+// common terminal fonts.
+const DefaultCellAspect = 2
+
+// TextOptions configures the dense text renderers of a QRCode.
+type TextOptions struct {
+	// QuietZone is the width of the quiet zone in modules. Zero keeps the
+	// default quiet zone and NoQuietZone removes it.
+	QuietZone int
+
+	// Inverse draws the dark modules instead of the light ones. By default
+	// light modules are drawn, which suits light text on a dark terminal.
+	Inverse bool
+
+	// CorrectAspect repeats modules horizontally so they come out roughly
+	// square with character cells of CellAspect.
+	CorrectAspect bool
+
+	// CellAspect is the height to width ratio of a character cell.
+	// Defaults to DefaultCellAspect.
+	CellAspect float64
+}
+
+var quadrantGlyphs = []string{
+	" ", "▘", "▝", "▀", "▖", "▌", "▞", "▛",
+	"▗", "▚", "▐", "▜", "▄", "▙", "▟", "█",
+}
+
+// ToQuadrantString returns a string representation of the QRCode using
+// quadrant block characters, with 2x2 modules per character.
+func (q *QRCode) ToQuadrantString(opts TextOptions) string {
+	return q.toCellString(opts, 2, 2, func(dots [][]bool) string {
+		var i int
+		for bit, dot := range []bool{dots[0][0], dots[0][1], dots[1][0], dots[1][1]} {
+			if dot {
+				i |= 1 << bit
+			}
+		}
+		return quadrantGlyphs[i]
+	})
+}
+
+// brailleDots maps a position within a 2x4 cell to its Braille dot.
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+// ToBrailleString returns a string representation of the QRCode using
+// Braille patterns, with 2x4 modules per character.
+func (q *QRCode) ToBrailleString(opts TextOptions) string {
+	return q.toCellString(opts, 2, 4, func(dots [][]bool) string {
+		r := rune(0x2800)
+		for y, row := range dots {
+			for x, dot := range row {
+				if dot {
+					r |= brailleDots[y][x]
+				}
+			}
+		}
+		return string(r)
+	})
+}
+
+// ToASCIIString returns a string representation of the QRCode using only
+// ASCII characters, with one module per character.
+func (q *QRCode) ToASCIIString(opts TextOptions) string {
+	return q.toCellString(opts, 1, 1, func(dots [][]bool) string {
+		if dots[0][0] {
+			return "#"
+		}
+		return " "
+	})
+}
+
+// toCellString packs the modules of the QRCode into character cells of
+// width x height modules and draws each cell with glyph. Modules past the
+// edge of the bitmap are treated as light.
+func (q *QRCode) toCellString(opts TextOptions, width, height int, glyph func(dots [][]bool) string) string {
+	bits := q.bitmapWithQuietZone(opts.QuietZone)
+
+	repeat := 1
+	if opts.CorrectAspect {
+		aspect := opts.CellAspect
+		if aspect <= 0 {
+			aspect = DefaultCellAspect
+		}
+		repeat = max(1, int(math.Round(aspect*float64(width)/float64(height))))
+	}
+
+	numRows := len(bits)
+	numCols := len(bits) * repeat
+	drawn := func(x, y int) bool {
+		if y >= numRows || x >= numCols {
+			return !opts.Inverse
+		}
+		return bits[y][x/repeat] == opts.Inverse
+	}
+
+	dots := make([][]bool, height)
+	for i := range dots {
+		dots[i] = make([]bool, width)
+	}
+
+	var buf strings.Builder
+	for y := 0; y < numRows; y += height {
+		for x := 0; x < numCols; x += width {
+			for j := range height {
+				for i := range width {
+					dots[j][i] = drawn(x+i, y+j)
+				}
+			}
+			buf.WriteString(glyph(dots))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
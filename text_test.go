@@ -0,0 +1,59 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestToASCIIString(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	expected := strings.ReplaceAll(q.ToString(false), "██", "##")
+	assert.Equal(t, q.ToASCIIString(TextOptions{CorrectAspect: true}), expected)
+
+	inverse := q.ToASCIIString(TextOptions{Inverse: true, QuietZone: NoQuietZone})
+	lines := strings.Split(strings.TrimSuffix(inverse, "\n"), "\n")
+	assert.Equal(t, lines[0][:7], "#######")
+}
+
+func TestDenseTextSize(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	size := len(q.Bitmap())
+
+	tests := []struct {
+		text          string
+		width, height int
+	}{
+		{q.ToQuadrantString(TextOptions{}), (size + 1) / 2, (size + 1) / 2},
+		{q.ToQuadrantString(TextOptions{CorrectAspect: true}), size, (size + 1) / 2},
+		{q.ToBrailleString(TextOptions{}), (size + 1) / 2, (size + 3) / 4},
+		{q.ToBrailleString(TextOptions{CorrectAspect: true}), (size + 1) / 2, (size + 3) / 4},
+	}
+
+	for _, test := range tests {
+		lines := strings.Split(strings.TrimSuffix(test.text, "\n"), "\n")
+		assert.Equal(t, len(lines), test.height)
+		for _, line := range lines {
+			assert.Equal(t, utf8.RuneCountInString(line), test.width)
+		}
+	}
+}
+
+func TestToBrailleStringQuietZone(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	text := q.ToBrailleString(TextOptions{QuietZone: 4, Inverse: true})
+	first, _ := utf8.DecodeRuneInString(text)
+	assert.Equal(t, first, rune(0x2800))
+
+	text = q.ToBrailleString(TextOptions{QuietZone: NoQuietZone, Inverse: true})
+	first, _ = utf8.DecodeRuneInString(text)
+	assert.Equal(t, first, rune(0x284f))
+}
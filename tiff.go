@@ -0,0 +1,106 @@
+package qrcode
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+)
+
+// TIFF tags and field types used by encodeTIFF.
+const (
+	tiffImageWidth                = 256
+	tiffImageLength               = 257
+	tiffBitsPerSample             = 258
+	tiffCompression               = 259
+	tiffPhotometricInterpretation = 262
+	tiffStripOffsets              = 273
+	tiffRowsPerStrip              = 278
+	tiffStripByteCounts           = 279
+	tiffXResolution               = 282
+	tiffYResolution               = 283
+	tiffResolutionUnit            = 296
+
+	tiffShort    = 3
+	tiffLong     = 4
+	tiffRational = 5
+)
+
+// encodeTIFF writes img as an uncompressed baseline bilevel TIFF, with one bit
+// per pixel. Pixels darker than mid grey over a white background are black.
+func encodeTIFF(w io.Writer, img image.Image, _ FormatOptions) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	rowLength := (width + 7) / 8
+
+	type entry struct {
+		tag, kind uint16
+		value     uint32
+	}
+
+	const (
+		headerLength   = 8
+		numEntries     = 11
+		ifdLength      = 2 + numEntries*12 + 4
+		rationalOffset = headerLength + ifdLength
+		dataOffset     = rationalOffset + 8
+	)
+
+	entries := [numEntries]entry{
+		{tiffImageWidth, tiffLong, uint32(width)},
+		{tiffImageLength, tiffLong, uint32(height)},
+		{tiffBitsPerSample, tiffShort, 1},
+		{tiffCompression, tiffShort, 1},
+		// WhiteIsZero, so set bits are black.
+		{tiffPhotometricInterpretation, tiffShort, 0},
+		{tiffStripOffsets, tiffLong, dataOffset},
+		{tiffRowsPerStrip, tiffLong, uint32(height)},
+		{tiffStripByteCounts, tiffLong, uint32(rowLength * height)},
+		{tiffXResolution, tiffRational, rationalOffset},
+		{tiffYResolution, tiffRational, rationalOffset},
+		// Inches.
+		{tiffResolutionUnit, tiffShort, 2},
+	}
+
+	header := make([]byte, 0, dataOffset)
+	header = append(header, 'I', 'I', 42, 0)
+	header = binary.LittleEndian.AppendUint32(header, headerLength)
+
+	header = binary.LittleEndian.AppendUint16(header, numEntries)
+	for _, e := range entries {
+		header = binary.LittleEndian.AppendUint16(header, e.tag)
+		header = binary.LittleEndian.AppendUint16(header, e.kind)
+		header = binary.LittleEndian.AppendUint32(header, 1)
+		if e.kind == tiffShort {
+			header = binary.LittleEndian.AppendUint16(header, uint16(e.value))
+			header = binary.LittleEndian.AppendUint16(header, 0)
+		} else {
+			header = binary.LittleEndian.AppendUint32(header, e.value)
+		}
+	}
+	header = binary.LittleEndian.AppendUint32(header, 0)
+
+	// 72 dpi.
+	header = binary.LittleEndian.AppendUint32(header, 72)
+	header = binary.LittleEndian.AppendUint32(header, 1)
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	white := toNRGBA(color.White)
+	data := make([]byte, rowLength*height)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := data[(y-b.Min.Y)*rowLength:]
+		for x := b.Min.X; x < b.Max.X; x++ {
+			n := over(toNRGBA(img.At(x, y)), white)
+			if color.GrayModel.Convert(n).(color.Gray).Y < 0x80 {
+				i := x - b.Min.X
+				row[i/8] |= 0x80 >> (i % 8)
+			}
+		}
+	}
+
+	_, err := w.Write(data)
+	return err
+}
@@ -0,0 +1,49 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestEncodeTIFF(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	bitmap := q.Bitmap()
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WriteFormat(&buf, "tiff", FormatOptions{Size: -1}))
+	data := buf.Bytes()
+
+	assert.Equal(t, string(data[:4]), "II*\x00")
+
+	ifd := int(binary.LittleEndian.Uint32(data[4:]))
+	numEntries := int(binary.LittleEndian.Uint16(data[ifd:]))
+	tags := map[uint16]uint32{}
+	for i := range numEntries {
+		e := data[ifd+2+i*12:]
+		value := binary.LittleEndian.Uint32(e[8:])
+		if binary.LittleEndian.Uint16(e[2:]) == tiffShort {
+			value = uint32(binary.LittleEndian.Uint16(e[8:]))
+		}
+		tags[binary.LittleEndian.Uint16(e)] = value
+	}
+
+	size := len(bitmap)
+	rowLength := (size + 7) / 8
+	assert.Equal(t, tags[tiffImageWidth], uint32(size))
+	assert.Equal(t, tags[tiffImageLength], uint32(size))
+	assert.Equal(t, tags[tiffBitsPerSample], uint32(1))
+	assert.Equal(t, tags[tiffCompression], uint32(1))
+	assert.Equal(t, tags[tiffStripByteCounts], uint32(rowLength*size))
+
+	strip := data[tags[tiffStripOffsets]:]
+	assert.Equal(t, len(strip), rowLength*size)
+	for y := range size {
+		for x := range size {
+			assert.Equal(t, strip[y*rowLength+x/8]&(0x80>>(x%8)) != 0, bitmap[y][x])
+		}
+	}
+}
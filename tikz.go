@@ -0,0 +1,204 @@
+package qrcode
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// DefaultTikZModuleLength is the default length of a module in TikZ output.
+const DefaultTikZModuleLength = "1mm"
+
+// texLength matches a length in one of the units of TeX.
+var texLength = regexp.MustCompile(`^[0-9]*\.?[0-9]+ ?(pt|pc|in|bp|cm|mm|dd|cc|sp|nd|nc|em|ex)$`)
+
+// TikZRenderer writes a LaTeX tikzpicture of the modules, drawn as
+// rectangles merged from runs of dark modules. The colors are defined with
+// \definecolor, from the xcolor package loaded by TikZ, as qrforeground and
+// qrbackground.
+type TikZRenderer struct {
+	// ModuleLength is the width of a module in a TeX unit, such as "1mm"
+	// or "2.5pt". Empty uses DefaultTikZModuleLength.
+	ModuleLength string
+
+	// ForegroundColor and BackgroundColor default to black and white. A
+	// transparent background is not painted.
+	ForegroundColor color.Color
+	BackgroundColor color.Color
+
+	// SinglePath draws all the rectangles with a single \fill command
+	// instead of one per rectangle.
+	SinglePath bool
+
+	// frame is drawn around the modules by QRCode.WriteTikZ.
+	frame *frameLayout
+}
+
+func (r TikZRenderer) Render(w io.Writer, m *ModuleMatrix) error {
+	length := r.ModuleLength
+	if length == "" {
+		length = DefaultTikZModuleLength
+	}
+	if !texLength.MatchString(length) {
+		return fmt.Errorf("invalid TeX length %q", length)
+	}
+
+	foreground, background := r.ForegroundColor, r.BackgroundColor
+	if foreground == nil {
+		foreground = color.Black
+	}
+	if background == nil {
+		background = color.White
+	}
+
+	buf := bufio.NewWriter(w)
+
+	// The y axis points down, like module coordinates.
+	fmt.Fprintf(buf, "\\begin{tikzpicture}[x=%s, y=-%s]\n", length, length)
+	writeTikZColor(buf, "qrforeground", foreground)
+	writeTikZColor(buf, "qrbackground", background)
+
+	canvas := frameLayout{w: float64(m.size), h: float64(m.size)}
+	if r.frame != nil {
+		canvas = *r.frame
+	}
+	if style := tikzFill("qrbackground", background); style != "" {
+		fmt.Fprintf(buf, "\\fill%s (%s,%s) rectangle (%s,%s);\n", style,
+			formatNumber(canvas.x), formatNumber(canvas.y),
+			formatNumber(canvas.x+canvas.w), formatNumber(canvas.y+canvas.h))
+	}
+
+	if style := tikzFill("qrforeground", foreground); style != "" {
+		rects := mergeDarkModules(m.dark)
+		if r.SinglePath {
+			fmt.Fprintf(buf, "\\fill%s", style)
+			for i, rect := range rects {
+				if i%4 == 0 {
+					buf.WriteString("\n ")
+				}
+				fmt.Fprintf(buf, " (%d,%d) rectangle (%d,%d)", rect.x, rect.y, rect.x+rect.w, rect.y+rect.h)
+			}
+			buf.WriteString(";\n")
+		} else {
+			for _, rect := range rects {
+				fmt.Fprintf(buf, "\\fill%s (%d,%d) rectangle (%d,%d);\n",
+					style, rect.x, rect.y, rect.x+rect.w, rect.y+rect.h)
+			}
+		}
+	}
+
+	paintFrame(&tikzPainter{w: buf}, canvas)
+
+	buf.WriteString("\\end{tikzpicture}\n")
+	return buf.Flush()
+}
+
+// tikzPainter fills paths with \fill commands, defining a color for each.
+type tikzPainter struct {
+	w      *bufio.Writer
+	colors int
+}
+
+func (v *tikzPainter) paint(f fill, evenOdd bool, draw func(p pathWriter)) {
+	name := fmt.Sprintf("qrframe%d", v.colors)
+	var options []string
+	if evenOdd {
+		options = append(options, "even odd rule")
+	}
+	style := tikzFill(name, f.color, options...)
+	if style == "" {
+		return
+	}
+
+	v.colors++
+	writeTikZColor(v.w, name, f.color)
+	fmt.Fprintf(v.w, "\\fill%s", style)
+	draw(tikzPath{v.w})
+	v.w.WriteString(";\n")
+}
+
+type tikzPath struct {
+	w *bufio.Writer
+}
+
+func (p tikzPath) moveTo(x, y float64) {
+	fmt.Fprintf(p.w, " (%s,%s)", formatNumber(x), formatNumber(y))
+}
+
+func (p tikzPath) lineTo(x, y float64) {
+	fmt.Fprintf(p.w, " -- (%s,%s)", formatNumber(x), formatNumber(y))
+}
+
+func (p tikzPath) curveTo(x1, y1, x2, y2, x, y float64) {
+	fmt.Fprintf(p.w, " .. controls (%s,%s) and (%s,%s) .. (%s,%s)",
+		formatNumber(x1), formatNumber(y1),
+		formatNumber(x2), formatNumber(y2),
+		formatNumber(x), formatNumber(y))
+}
+
+func (p tikzPath) closePath() {
+	p.w.WriteString(" -- cycle")
+}
+
+func (p tikzPath) rect(x, y, w, h int) {
+	fmt.Fprintf(p.w, " (%d,%d) rectangle (%d,%d)", x, y, x+w, y+h)
+}
+
+// writeTikZColor defines the RGB color name from c.
+func writeTikZColor(w io.Writer, name string, c color.Color) {
+	n := toNRGBA(c)
+	fmt.Fprintf(w, "\\definecolor{%s}{RGB}{%d,%d,%d}\n", name, n.R, n.G, n.B)
+}
+
+// tikzFill returns the options filling a path with the color name, followed by
+// extra, or an empty string when c is fully transparent.
+func tikzFill(name string, c color.Color, extra ...string) string {
+	n := toNRGBA(c)
+	if n.A == 0 {
+		return ""
+	}
+
+	options := append([]string{name}, extra...)
+	if n.A != 0xff {
+		options = append(options, fmt.Sprintf("fill opacity=%.3g", float64(n.A)/0xff))
+	}
+	return "[" + strings.Join(options, ", ") + "]"
+}
+
+// TikZOptions configures the TikZ output of a QRCode.
+type TikZOptions struct {
+	// ModuleLength is the width of a module in a TeX unit. Empty uses
+	// DefaultTikZModuleLength.
+	ModuleLength string
+
+	// QuietZone is the width of the quiet zone in modules. Zero keeps the
+	// default quiet zone and NoQuietZone removes it.
+	QuietZone int
+
+	// SinglePath draws the modules with a single \fill command.
+	SinglePath bool
+}
+
+// WriteTikZ writes a LaTeX tikzpicture of the QRCode in its foreground and
+// background colors, with its frame. Modules are drawn as squares.
+func (q *QRCode) WriteTikZ(out io.Writer, opts TikZOptions) error {
+	if err := q.checkColors(); err != nil {
+		return err
+	}
+
+	m := q.Matrix(opts.QuietZone)
+	r := TikZRenderer{
+		ModuleLength:    opts.ModuleLength,
+		ForegroundColor: q.ForegroundColor,
+		BackgroundColor: q.BackgroundColor,
+		SinglePath:      opts.SinglePath,
+	}
+	if q.frame != nil {
+		frame := q.frame.layout(m, q.ForegroundColor, q.BackgroundColor)
+		r.frame = &frame
+	}
+	return r.Render(out, m)
+}
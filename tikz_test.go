@@ -0,0 +1,89 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+var tikzRectangle = regexp.MustCompile(`\((\d+),(\d+)\) rectangle \((\d+),(\d+)\)`)
+
+// tikzModules returns the modules covered by the foreground rectangles of a
+// tikzpicture.
+func tikzModules(t *testing.T, tikz string, size int) [][]bool {
+	modules := make([][]bool, size)
+	for y := range modules {
+		modules[y] = make([]bool, size)
+	}
+
+	_, foreground, _ := strings.Cut(tikz, `\fill[qrforeground`)
+	for _, match := range tikzRectangle.FindAllStringSubmatch(foreground, -1) {
+		var v [4]int
+		for i := range v {
+			n, err := strconv.Atoi(match[i+1])
+			assert.NoError(t, err)
+			v[i] = n
+		}
+		for y := v[1]; y < v[3]; y++ {
+			for x := v[0]; x < v[2]; x++ {
+				modules[y][x] = true
+			}
+		}
+	}
+
+	return modules
+}
+
+func TestWriteTikZ(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	q.WithColors(navy, color.White)
+	m := q.Matrix(NoQuietZone)
+
+	for _, single := range []bool{false, true} {
+		var buf bytes.Buffer
+		assert.NoError(t, q.WriteTikZ(&buf, TikZOptions{ModuleLength: "0.5mm", QuietZone: NoQuietZone, SinglePath: single}))
+		out := buf.String()
+
+		assert.True(t, strings.HasPrefix(out, "\\begin{tikzpicture}[x=0.5mm, y=-0.5mm]\n"))
+		assert.True(t, strings.HasSuffix(out, "\\end{tikzpicture}\n"))
+		assert.True(t, strings.Contains(out, "\\definecolor{qrforeground}{RGB}{0,32,96}\n"))
+		assert.True(t, strings.Contains(out, fmt.Sprintf("\\fill[qrbackground] (0,0) rectangle (%d,%d);\n", m.Size(), m.Size())))
+		assert.Equal(t, tikzModules(t, out, m.Size()), m.dark)
+
+		fills := strings.Count(out, `\fill[qrforeground]`)
+		if single {
+			assert.Equal(t, fills, 1)
+		} else {
+			assert.Equal(t, fills, len(mergeDarkModules(m.dark)))
+		}
+	}
+}
+
+func TestTikZRenderer(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.RenderNamed(&buf, "tikz"))
+	assert.True(t, strings.Contains(buf.String(), "[x=1mm, y=-1mm]"))
+
+	buf.Reset()
+	r := TikZRenderer{ForegroundColor: color.NRGBA{0, 0, 0, 0x80}, BackgroundColor: color.Transparent}
+	assert.NoError(t, q.Render(&buf, r))
+	assert.False(t, strings.Contains(buf.String(), `\fill[qrbackground]`))
+	assert.True(t, strings.Contains(buf.String(), `\fill[qrforeground, fill opacity=0.502]`))
+
+	for _, length := range []string{"12 pt", "2in", ".5cm", "1.25ex"} {
+		assert.NoError(t, q.Render(&buf, TikZRenderer{ModuleLength: length}))
+	}
+	for _, length := range []string{"1", "mm", "1furlong", `1mm]\foo`} {
+		assert.Error(t, q.Render(&buf, TikZRenderer{ModuleLength: length}))
+	}
+}
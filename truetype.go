@@ -0,0 +1,149 @@
+package qrcode
+
+import (
+	"fmt"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// trueTypeFont is a Font drawing the outlines of a TrueType or OpenType font.
+// Glyphs are loaded at one pixel per font unit, so their outlines are exact,
+// and scaled to the size asked.
+type trueTypeFont struct {
+	f    *sfnt.Font
+	ppem fixed.Int26_6
+
+	// ascent and height are the distance from the top of a line to the
+	// baseline and the height of a line, in font units.
+	ascent, height float64
+}
+
+// NewTrueTypeFont returns a Font drawing captions with the TrueType or
+// OpenType font in data. A line of text size units tall spans the ascent and
+// descent of the font. Characters missing from the font are drawn as '?'.
+func NewTrueTypeFont(data []byte) (Font, error) {
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse font: %w", err)
+	}
+
+	ppem := fixed.Int26_6(f.UnitsPerEm()) << 6
+	m, err := f.Metrics(nil, ppem, font.HintingNone)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read font metrics: %w", err)
+	}
+
+	t := &trueTypeFont{
+		f:      f,
+		ppem:   ppem,
+		ascent: unfix(m.Ascent),
+		height: unfix(m.Ascent + m.Descent),
+	}
+	if t.height <= 0 {
+		return nil, fmt.Errorf("font has an invalid line height of %g units", t.height)
+	}
+
+	return t, nil
+}
+
+// unfix converts a 26.6 fixed-point value to a float.
+func unfix(v fixed.Int26_6) float64 {
+	return float64(v) / 64
+}
+
+// glyphs returns the glyphs of s, replacing missing characters by the glyph
+// of '?'.
+func (t *trueTypeFont) glyphs(buf *sfnt.Buffer, s string) []sfnt.GlyphIndex {
+	var glyphs []sfnt.GlyphIndex
+	for _, r := range s {
+		g, err := t.f.GlyphIndex(buf, r)
+		if err != nil || g == 0 {
+			g, _ = t.f.GlyphIndex(buf, '?')
+		}
+		glyphs = append(glyphs, g)
+	}
+	return glyphs
+}
+
+// advances returns the position of every glyph from the start of the line,
+// and the width of the line, in font units.
+func (t *trueTypeFont) advances(buf *sfnt.Buffer, glyphs []sfnt.GlyphIndex) ([]float64, float64) {
+	positions := make([]float64, len(glyphs))
+	x := 0.0
+	for i, g := range glyphs {
+		if i > 0 {
+			// Fonts without a kerning table report an error.
+			if kern, err := t.f.Kern(buf, glyphs[i-1], g, t.ppem, font.HintingNone); err == nil {
+				x += unfix(kern)
+			}
+		}
+		positions[i] = x
+
+		advance, err := t.f.GlyphAdvance(buf, g, t.ppem, font.HintingNone)
+		if err == nil {
+			x += unfix(advance)
+		}
+	}
+	return positions, x
+}
+
+func (t *trueTypeFont) Advance(s string, size float64) float64 {
+	var buf sfnt.Buffer
+	_, width := t.advances(&buf, t.glyphs(&buf, s))
+	return width * size / t.height
+}
+
+func (t *trueTypeFont) Outline(p PathWriter, s string, x, y, size float64) {
+	var buf sfnt.Buffer
+	glyphs := t.glyphs(&buf, s)
+	positions, _ := t.advances(&buf, glyphs)
+
+	scale := size / t.height
+	baseline := y + t.ascent*scale
+
+	for i, g := range glyphs {
+		segments, err := t.f.LoadGlyph(&buf, g, t.ppem, nil)
+		if err != nil {
+			continue
+		}
+
+		// Glyph coordinates grow downwards from the origin of the glyph on
+		// the baseline.
+		left := x + positions[i]*scale
+		point := func(v fixed.Point26_6) (float64, float64) {
+			return left + unfix(v.X)*scale, baseline + unfix(v.Y)*scale
+		}
+
+		var cx, cy float64
+		open := false
+		for _, seg := range segments {
+			switch seg.Op {
+			case sfnt.SegmentOpMoveTo:
+				if open {
+					p.ClosePath()
+				}
+				cx, cy = point(seg.Args[0])
+				p.MoveTo(cx, cy)
+				open = true
+			case sfnt.SegmentOpLineTo:
+				cx, cy = point(seg.Args[0])
+				p.LineTo(cx, cy)
+			case sfnt.SegmentOpQuadTo:
+				qx, qy := point(seg.Args[0])
+				ex, ey := point(seg.Args[1])
+				p.CurveTo(cx+2*(qx-cx)/3, cy+2*(qy-cy)/3, ex+2*(qx-ex)/3, ey+2*(qy-ey)/3, ex, ey)
+				cx, cy = ex, ey
+			case sfnt.SegmentOpCubeTo:
+				x1, y1 := point(seg.Args[0])
+				x2, y2 := point(seg.Args[1])
+				cx, cy = point(seg.Args[2])
+				p.CurveTo(x1, y1, x2, y2, cx, cy)
+			}
+		}
+		if open {
+			p.ClosePath()
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package qrcode
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestTrueTypeFont(t *testing.T) {
+	_, err := NewTrueTypeFont([]byte("not a font"))
+	assert.Error(t, err)
+
+	f, err := NewTrueTypeFont(goregular.TTF)
+	assert.NoError(t, err)
+
+	assert.Equal(t, f.Advance("", 10), 0.0)
+	width := f.Advance("QR", 10)
+	assert.True(t, width > 0)
+	assert.True(t, math.Abs(f.Advance("QR", 20)-2*width) < 1e-9)
+
+	outline := func(s string) *rasterShape {
+		r := newRasterShape(frameShape{color: color.Black, draw: func(p pathWriter) {
+			f.Outline(pathAdapter{p}, s, 0, 0, 100)
+		}})
+		return &r
+	}
+
+	// The glyphs stay within the line, and an 'l' inks its middle.
+	text := outline("lQj")
+	for _, b := range text.bounds {
+		assert.True(t, b.x0 >= 0 && b.x1 <= f.Advance("lQj", 100))
+		assert.True(t, b.y0 >= 0 && b.y1 <= 100)
+	}
+	l := f.Advance("l", 100)
+	assert.True(t, text.contains(l/2, 50))
+	assert.False(t, text.contains(l/2, 2))
+
+	// Characters missing from the font are drawn as '?'.
+	missing, question := outline("中"), outline("?")
+	assert.Equal(t, f.Advance("中", 100), f.Advance("?", 100))
+	for y := 0.5; y < 100; y += 2 {
+		for x := 0.5; x < f.Advance("?", 100); x += 2 {
+			assert.Equal(t, missing.contains(x, y), question.contains(x, y))
+		}
+	}
+}
+
+func TestFrameWithTrueTypeFont(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	m := q.Matrix(0)
+	n := float64(m.size)
+
+	f, err := NewTrueTypeFont(goregular.TTF)
+	assert.NoError(t, err)
+	assert.NoError(t, q.WithFrame(FrameOptions{Template: FrameScanMe, Caption: "i9si", Font: f}))
+
+	layout := q.frame.layout(m, q.ForegroundColor, q.BackgroundColor)
+	for _, s := range layout.shapes {
+		r := newRasterShape(s)
+		for y := 0.125; y < n; y += 0.25 {
+			for x := 0.125; x < n; x += 0.25 {
+				assert.False(t, r.contains(x, y))
+			}
+		}
+	}
+	assert.NotNil(t, q.PNG(256))
+}
@@ -0,0 +1,46 @@
+package qrcode
+
+import (
+	"math"
+	"strconv"
+)
+
+// pathWriter receives the outlines of a vector renderer. Coordinates are in
+// modules, with y growing downwards.
+type pathWriter interface {
+	moveTo(x, y float64)
+	lineTo(x, y float64)
+	curveTo(x1, y1, x2, y2, x, y float64)
+	closePath()
+	rect(x, y, w, h int)
+}
+
+// vectorPainter fills outlines in a vector renderer.
+type vectorPainter interface {
+	// paint fills the outlines written by draw with f, using the
+	// even-odd rule if evenOdd is set and the nonzero rule otherwise.
+	paint(f fill, evenOdd bool, draw func(p pathWriter))
+}
+
+// drawVector paints the dark modules and the styled eyes of the QRCode.
+func (q *QRCode) drawVector(v vectorPainter, m *ModuleMatrix) {
+	for _, l := range q.layers(m) {
+		v.paint(l.fill, false, func(p pathWriter) {
+			m.writePaths(p, q.ModuleShape, l.roles)
+		})
+	}
+
+	for _, e := range q.eyes(m) {
+		v.paint(e.outerFill, true, e.writeRing)
+		v.paint(e.innerFill, false, e.inner.path)
+	}
+}
+
+func formatNumber(v float64) string {
+	v = math.Round(v*1e4) / 1e4
+	if v == 0 {
+		// Drop the sign of negative zero.
+		v = 0
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
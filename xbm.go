@@ -0,0 +1,83 @@
+package qrcode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+// DefaultXBMName is the default prefix of the identifiers in XBM output.
+const DefaultXBMName = "qrcode"
+
+// XBMOptions configures the X BitMap output of a QRCode.
+type XBMOptions struct {
+	// Scale is the width of a module in pixels. Zero uses 1.
+	Scale int
+
+	// QuietZone is the width of the quiet zone in modules. Zero keeps the
+	// default quiet zone and NoQuietZone removes it.
+	QuietZone int
+
+	// Name prefixes the _width, _height and _bits identifiers. It must be
+	// a valid C identifier. Empty uses DefaultXBMName.
+	Name string
+}
+
+var cIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// xbmBytesPerLine is the number of bytes on each line of the bits array.
+const xbmBytesPerLine = 12
+
+// WriteXBM writes an X BitMap of the QRCode: C source defining its width,
+// height and a static char array of its pixels, with dark modules as 1.
+func (q *QRCode) WriteXBM(out io.Writer, opts XBMOptions) error {
+	if opts.Name == "" {
+		opts.Name = DefaultXBMName
+	}
+	if !cIdentifier.MatchString(opts.Name) {
+		return fmt.Errorf("XBM name %q is not a C identifier", opts.Name)
+	}
+	if err := q.checkUnframed("XBM"); err != nil {
+		return err
+	}
+
+	rows, err := q.scaledBitmap(opts.Scale, opts.QuietZone)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(out)
+	fmt.Fprintf(w, "#define %s_width %d\n", opts.Name, len(rows[0]))
+	fmt.Fprintf(w, "#define %s_height %d\n", opts.Name, len(rows))
+	fmt.Fprintf(w, "static unsigned char %s_bits[] = {", opts.Name)
+
+	// Rows are padded to whole bytes, with the leftmost pixel in the
+	// lowest bit.
+	n := 0
+	packed := make([]byte, (len(rows[0])+7)/8)
+	for _, row := range rows {
+		clear(packed)
+		for x, dark := range row {
+			if dark {
+				packed[x/8] |= 1 << (x % 8)
+			}
+		}
+
+		for _, b := range packed {
+			if n > 0 {
+				w.WriteByte(',')
+			}
+			if n%xbmBytesPerLine == 0 {
+				w.WriteString("\n  ")
+			} else {
+				w.WriteByte(' ')
+			}
+			fmt.Fprintf(w, "0x%02x", b)
+			n++
+		}
+	}
+	w.WriteString(" };\n")
+
+	return w.Flush()
+}
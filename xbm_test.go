@@ -0,0 +1,49 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/i9si-sistemas/assert"
+)
+
+func TestWriteXBM(t *testing.T) {
+	q, err := New(i9siDomain, Medium)
+	assert.NoError(t, err)
+	bitmap := q.Bitmap()
+	n := len(bitmap)
+
+	var buf bytes.Buffer
+	assert.NoError(t, q.WriteXBM(&buf, XBMOptions{Name: "label_qr"}))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, fmt.Sprintf("#define label_qr_width %d\n#define label_qr_height %d\n", n, n)))
+	assert.True(t, strings.HasSuffix(out, " };\n"))
+
+	start := strings.Index(out, "{") + 1
+	end := strings.Index(out, "}")
+	var data []byte
+	for _, f := range strings.Split(out[start:end], ",") {
+		v, err := strconv.ParseUint(strings.TrimSpace(f), 0, 8)
+		assert.NoError(t, err)
+		data = append(data, byte(v))
+	}
+
+	stride := (n + 7) / 8
+	assert.Equal(t, len(data), n*stride)
+	for y := range n {
+		for x := range n {
+			assert.Equal(t, data[y*stride+x/8]&(1<<(x%8)) != 0, bitmap[y][x])
+		}
+	}
+
+	buf.Reset()
+	assert.NoError(t, q.WriteXBM(&buf, XBMOptions{}))
+	assert.True(t, strings.Contains(buf.String(), "static unsigned char qrcode_bits[] = {"))
+
+	assert.Error(t, q.WriteXBM(&buf, XBMOptions{Name: "9lives"}))
+	assert.Error(t, q.WriteXBM(&buf, XBMOptions{Name: "a-b"}))
+}